@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dynamicTTL 动态来源（含域名解析结果）的缓存时长。
+const dynamicTTL = 30 * time.Second
+
+// lookupTimeout 单个域名解析的超时时间。
+const lookupTimeout = 5 * time.Second
+
+// lookupIP 域名解析函数，测试可替换。
+var lookupIP = func(ctx context.Context, host string) ([]net.IP, error) {
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// IPAllowList 来源 IP/CIDR 白名单。
+// 说明：按 r.RemoteAddr 判断，不信任 X-Forwarded-For；部署在反向代理之后时应由代理负责来源校验。
+type IPAllowList struct {
+	static []*net.IPNet
+
+	mu         sync.Mutex
+	dynamic    func() []string
+	gen        uint64                  // WithDynamic 每次调用递增，丢弃旧来源的刷新结果
+	cached     []*net.IPNet            // 最近一次刷新的合并结果
+	lastGood   map[string][]*net.IPNet // 按条目记录最近一次成功解析的结果
+	loaded     bool                    // 是否已完成过刷新
+	expires    time.Time
+	refreshing chan struct{} // 刷新进行中时非 nil，刷新结束后关闭
+}
+
+// AllowList 由 IP 或 CIDR 列表构造白名单。
+// 异常：条目既不是合法 IP 也不是合法 CIDR 时返回错误。
+func AllowList(entries ...string) (*IPAllowList, error) {
+	a := &IPAllowList{}
+	for _, e := range entries {
+		n, err := parseEntry(e)
+		if err != nil {
+			return nil, err
+		}
+		a.static = append(a.static, n)
+	}
+	return a, nil
+}
+
+// WithDynamic 追加动态来源，如服务发现得到的 Server 地址；条目可为 IP、CIDR、host 或 host:port，
+// 域名会被解析为 IP。结果缓存 30 秒，过期后在后台刷新，刷新期间继续使用旧结果；
+// 某个域名解析失败时保留其上一次成功的结果。返回自身便于链式调用。
+func (a *IPAllowList) WithDynamic(fn func() []string) *IPAllowList {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.dynamic, a.expires = fn, time.Time{}
+	a.gen++
+	a.cached, a.lastGood, a.loaded = nil, nil, false
+	return a
+}
+
+// Authenticate 实现 Authenticator。
+func (a *IPAllowList) Authenticate(r *http.Request, body []byte) error {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return forbidden("unknown remote address %q", r.RemoteAddr)
+	}
+	for _, n := range a.static {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	for _, n := range a.dynamicNets() {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	return forbidden("remote address %s not allowed", ip)
+}
+
+// dynamicNets 返回动态来源的网段。
+// 说明：缓存过期时启动后台刷新并立即返回旧结果，DNS 解析不在锁内、也不阻塞请求；
+// 仅首次加载（尚无任何结果）时等待刷新完成。
+func (a *IPAllowList) dynamicNets() []*net.IPNet {
+	a.mu.Lock()
+	if a.dynamic == nil {
+		a.mu.Unlock()
+		return nil
+	}
+	if a.loaded && time.Now().Before(a.expires) {
+		nets := a.cached
+		a.mu.Unlock()
+		return nets
+	}
+	done := a.refreshing
+	if done == nil {
+		done = make(chan struct{})
+		a.refreshing = done
+		go a.refresh(a.dynamic, a.gen, a.lastGood, done)
+	}
+	if a.loaded {
+		nets := a.cached
+		a.mu.Unlock()
+		return nets
+	}
+	a.mu.Unlock()
+	<-done
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.cached
+}
+
+// refresh 在锁外解析动态条目并更新缓存；解析失败的条目沿用 prev 中上一次成功的结果。
+func (a *IPAllowList) refresh(fn func() []string, gen uint64, prev map[string][]*net.IPNet, done chan struct{}) {
+	defer close(done)
+	next := map[string][]*net.IPNet{}
+	var out []*net.IPNet
+	for _, e := range fn() {
+		nets, err := resolveEntry(e)
+		if err != nil {
+			nets = prev[e]
+		}
+		if nets != nil {
+			next[e] = nets
+		}
+		out = append(out, nets...)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.refreshing == done {
+		a.refreshing = nil
+	}
+	if gen != a.gen {
+		return
+	}
+	a.cached, a.lastGood, a.loaded, a.expires = out, next, true, time.Now().Add(dynamicTTL)
+}
+
+// parseEntry 解析 IP 或 CIDR。
+func parseEntry(e string) (*net.IPNet, error) {
+	e = strings.TrimSpace(e)
+	if strings.Contains(e, "/") {
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			return nil, fmt.Errorf("auth: bad CIDR %q: %w", e, err)
+		}
+		return n, nil
+	}
+	ip := net.ParseIP(e)
+	if ip == nil {
+		return nil, fmt.Errorf("auth: bad IP %q", e)
+	}
+	return hostNet(ip), nil
+}
+
+// resolveEntry 解析动态条目：去掉 scheme 与端口，域名做 DNS 解析。
+// 异常：域名解析失败时返回错误，由调用方决定是否沿用旧结果。
+func resolveEntry(e string) ([]*net.IPNet, error) {
+	if _, rest, ok := strings.Cut(e, "://"); ok {
+		e = rest
+	}
+	if n, err := parseEntry(e); err == nil {
+		return []*net.IPNet{n}, nil
+	}
+	host := e
+	if h, _, err := net.SplitHostPort(e); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return []*net.IPNet{hostNet(ip)}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	ips, err := lookupIP(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*net.IPNet, 0, len(ips))
+	for _, ip := range ips {
+		out = append(out, hostNet(ip))
+	}
+	return out, nil
+}
+
+func hostNet(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
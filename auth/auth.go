@@ -0,0 +1,92 @@
+// Package auth 提供 Worker 入站请求的可插拔鉴权。
+//
+// 内置实现：
+//   - HMAC：共享密钥签名，携带时间戳与随机串，在时间窗口内拒绝重放；
+//   - Bearer：静态令牌；
+//   - AllowList：来源 IP/CIDR 白名单，可动态追加（如服务发现得到的 Server 地址）。
+//
+// 多个鉴权器可通过 All / Any 组合。被拒绝的请求由 Worker 写入审计日志。
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// 拒绝原因哨兵错误：ErrUnauthenticated 对应 401，ErrForbidden 对应 403。
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+// Authenticator 校验入站请求。
+// 参数：r 原始请求；body 已读取的请求体（r.Body 已被替换为可重复读取的副本）。
+// 返回：nil 表示放行；拒绝时应返回包装 ErrUnauthenticated 或 ErrForbidden 的错误。
+type Authenticator interface {
+	Authenticate(r *http.Request, body []byte) error
+}
+
+// Func 函数式 Authenticator。
+type Func func(r *http.Request, body []byte) error
+
+// Authenticate 实现 Authenticator。
+func (f Func) Authenticate(r *http.Request, body []byte) error { return f(r, body) }
+
+// All 要求全部鉴权器通过，按顺序返回第一个错误；nil 项被忽略。
+func All(as ...Authenticator) Authenticator {
+	return Func(func(r *http.Request, body []byte) error {
+		for _, a := range as {
+			if a == nil {
+				continue
+			}
+			if err := a.Authenticate(r, body); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Any 任一鉴权器通过即放行；全部拒绝时返回合并后的错误。
+func Any(as ...Authenticator) Authenticator {
+	return Func(func(r *http.Request, body []byte) error {
+		var errs []error
+		for _, a := range as {
+			if a == nil {
+				continue
+			}
+			err := a.Authenticate(r, body)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return nil
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// unauthenticated/forbidden 构造带原因的拒绝错误。
+func unauthenticated(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrUnauthenticated, fmt.Sprintf(format, args...))
+}
+
+func forbidden(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrForbidden, fmt.Sprintf(format, args...))
+}
+
+// AuditEvent 被拒绝的入站请求审计记录。
+type AuditEvent struct {
+	Time       time.Time
+	RemoteAddr string
+	Method     string
+	Path       string
+	Reason     string // 拒绝原因（鉴权器返回的错误文本）
+}
+
+// AuditFunc 审计日志输出函数。
+type AuditFunc func(ev AuditEvent)
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newReq(body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/worker/runJob", strings.NewReader(body))
+	r.RemoteAddr = "10.1.2.3:5555"
+	return r
+}
+
+func TestHMAC(t *testing.T) {
+	Convey("HMAC should verify signatures, enforce the window and reject replays", t, func() {
+		secret := []byte("s3cret")
+		a := HMAC(time.Minute, []byte("old"), secret)
+		body := []byte(`{"instanceId":1}`)
+
+		r := newReq(string(body))
+		Sign(r, secret, body)
+		So(a.Authenticate(r, body), ShouldBeNil)
+
+		Convey("replaying the same request is rejected", func() {
+			err := a.Authenticate(r, body)
+			So(errors.Is(err, ErrUnauthenticated), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "replayed")
+		})
+
+		Convey("tampered bodies and wrong secrets are rejected", func() {
+			r2 := newReq(string(body))
+			Sign(r2, secret, body)
+			So(a.Authenticate(r2, []byte(`{"instanceId":2}`)), ShouldNotBeNil)
+			r3 := newReq(string(body))
+			Sign(r3, []byte("other"), body)
+			So(a.Authenticate(r3, body), ShouldNotBeNil)
+		})
+
+		Convey("stale timestamps are rejected", func() {
+			a.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+			r4 := newReq(string(body))
+			Sign(r4, secret, body)
+			err := a.Authenticate(r4, body)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "window")
+		})
+
+		Convey("missing headers are rejected", func() {
+			So(a.Authenticate(newReq(""), nil), ShouldNotBeNil)
+		})
+
+		Convey("expired nonces are swept from the front of the queue", func() {
+			now := time.Now()
+			So(a.remember("n1", now), ShouldBeTrue)
+			So(a.remember("n2", now.Add(time.Second)), ShouldBeTrue)
+			So(a.remember("n1", now.Add(2*time.Second)), ShouldBeFalse)
+			So(a.remember("n3", now.Add(2*time.Minute+500*time.Millisecond)), ShouldBeTrue)
+			So(len(a.nonces), ShouldEqual, 2) // 初始请求的 nonce 与 n1 已过期
+			So(len(a.order), ShouldEqual, 2)
+			So(a.remember("n1", now.Add(2*time.Minute+500*time.Millisecond)), ShouldBeTrue)
+		})
+	})
+}
+
+func TestBearerAndAllowList(t *testing.T) {
+	Convey("Bearer should accept any configured token", t, func() {
+		b := Bearer("new", "old")
+		r := newReq("")
+		So(errors.Is(b.Authenticate(r, nil), ErrUnauthenticated), ShouldBeTrue)
+		r.Header.Set("Authorization", "Bearer old")
+		So(b.Authenticate(r, nil), ShouldBeNil)
+		r.Header.Set("Authorization", "Bearer nope")
+		So(b.Authenticate(r, nil), ShouldNotBeNil)
+	})
+
+	Convey("AllowList should match static CIDRs and dynamic server addresses", t, func() {
+		_, err := AllowList("not-an-ip")
+		So(err, ShouldNotBeNil)
+
+		a, err := AllowList("192.168.0.0/16", "10.9.9.9")
+		So(err, ShouldBeNil)
+		r := newReq("")
+		So(errors.Is(a.Authenticate(r, nil), ErrForbidden), ShouldBeTrue)
+
+		a.WithDynamic(func() []string { return []string{"http://10.1.2.3:7700", "garbage host name.invalid"} })
+		So(a.Authenticate(r, nil), ShouldBeNil)
+		r.RemoteAddr = "192.168.3.4:1"
+		So(a.Authenticate(r, nil), ShouldBeNil)
+	})
+
+	Convey("AllowList should refresh DNS off the request path and keep the last good result", t, func() {
+		orig := lookupIP
+		defer func() { lookupIP = orig }()
+		release := make(chan struct{})
+		calls := 0
+		lookupIP = func(ctx context.Context, host string) ([]net.IP, error) {
+			calls++
+			if calls == 1 {
+				return []net.IP{net.ParseIP("10.5.5.5")}, nil
+			}
+			<-release
+			return nil, errors.New("dns down")
+		}
+		a, _ := AllowList()
+		a.WithDynamic(func() []string { return []string{"server.test:7700"} })
+		r := newReq("")
+		r.RemoteAddr = "10.5.5.5:1"
+		So(a.Authenticate(r, nil), ShouldBeNil)
+
+		// 缓存过期：请求不等待阻塞中的解析，直接使用旧结果
+		a.mu.Lock()
+		a.expires = time.Time{}
+		a.mu.Unlock()
+		start := time.Now()
+		So(a.Authenticate(r, nil), ShouldBeNil)
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+
+		// 解析失败后仍保留上一次成功的结果
+		a.mu.Lock()
+		done := a.refreshing
+		a.mu.Unlock()
+		So(done, ShouldNotBeNil)
+		close(release)
+		<-done
+		So(a.Authenticate(r, nil), ShouldBeNil)
+	})
+
+	Convey("All and Any should combine authenticators", t, func() {
+		ok := Func(func(*http.Request, []byte) error { return nil })
+		deny := Func(func(*http.Request, []byte) error { return forbidden("no") })
+		r := newReq("")
+		So(All(ok, nil, ok).Authenticate(r, nil), ShouldBeNil)
+		So(errors.Is(All(ok, deny).Authenticate(r, nil), ErrForbidden), ShouldBeTrue)
+		So(Any(deny, ok).Authenticate(r, nil), ShouldBeNil)
+		So(errors.Is(Any(deny, deny).Authenticate(r, nil), ErrForbidden), ShouldBeTrue)
+	})
+}
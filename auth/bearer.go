@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Bearer 校验 `Authorization: Bearer <token>`，tokens 中任一匹配即放行（常量时间比较）。
+// 说明：支持同时配置新旧两个令牌以便平滑轮换。
+func Bearer(tokens ...string) Authenticator {
+	return Func(func(r *http.Request, body []byte) error {
+		h := r.Header.Get("Authorization")
+		got, ok := strings.CutPrefix(h, "Bearer ")
+		if !ok || got == "" {
+			return unauthenticated("missing bearer token")
+		}
+		for _, t := range tokens {
+			if t != "" && subtle.ConstantTimeCompare([]byte(got), []byte(t)) == 1 {
+				return nil
+			}
+		}
+		return unauthenticated("invalid bearer token")
+	})
+}
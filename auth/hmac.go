@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HMAC 签名使用的请求头。
+const (
+	HeaderTimestamp = "X-PowerJob-Timestamp" // 毫秒时间戳
+	HeaderNonce     = "X-PowerJob-Nonce"     // 每次请求唯一的随机串
+	HeaderSignature = "X-PowerJob-Signature" // hex(HMAC-SHA256(secret, StringToSign))
+)
+
+// DefaultHMACWindow 默认时间窗口：时间戳偏差超过窗口的请求被拒绝，窗口内的 nonce 不可重复。
+const DefaultHMACWindow = 5 * time.Minute
+
+// StringToSign 待签名串：METHOD \n PATH \n TIMESTAMP \n NONCE \n BODY。
+func StringToSign(method, path, ts, nonce string, body []byte) []byte {
+	b := make([]byte, 0, len(method)+len(path)+len(ts)+len(nonce)+len(body)+4)
+	b = append(b, method...)
+	b = append(b, '\n')
+	b = append(b, path...)
+	b = append(b, '\n')
+	b = append(b, ts...)
+	b = append(b, '\n')
+	b = append(b, nonce...)
+	b = append(b, '\n')
+	return append(b, body...)
+}
+
+// Sign 为请求写入时间戳、nonce 与签名头（供调用方或测试使用），body 须与实际发送的请求体一致。
+func Sign(r *http.Request, secret, body []byte) {
+	ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	var nb [12]byte
+	_, _ = rand.Read(nb[:])
+	nonce := hex.EncodeToString(nb[:])
+	r.Header.Set(HeaderTimestamp, ts)
+	r.Header.Set(HeaderNonce, nonce)
+	r.Header.Set(HeaderSignature, signature(secret, StringToSign(r.Method, r.URL.Path, ts, nonce, body)))
+}
+
+func signature(secret, msg []byte) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write(msg)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// HMACAuth 共享密钥签名鉴权器，内置窗口期 nonce 缓存以拒绝重放。
+type HMACAuth struct {
+	secrets [][]byte
+	window  time.Duration
+	now     func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]time.Time // nonce -> 过期时间
+	order  []nonceEntry         // 按记录顺序（即过期时间）排列，用于增量清理
+}
+
+// nonceEntry 待过期的 nonce。
+type nonceEntry struct {
+	nonce string
+	exp   time.Time
+}
+
+// HMAC 创建签名鉴权器；secrets 中任一密钥校验通过即放行，便于密钥轮换。
+// 参数：window 时间窗口（<=0 时取 DefaultHMACWindow）。
+func HMAC(window time.Duration, secrets ...[]byte) *HMACAuth {
+	if window <= 0 {
+		window = DefaultHMACWindow
+	}
+	return &HMACAuth{secrets: secrets, window: window, now: time.Now, nonces: map[string]time.Time{}}
+}
+
+// Authenticate 实现 Authenticator。
+func (h *HMACAuth) Authenticate(r *http.Request, body []byte) error {
+	ts, nonce, sig := r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderNonce), r.Header.Get(HeaderSignature)
+	if ts == "" || nonce == "" || sig == "" {
+		return unauthenticated("missing signature headers")
+	}
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return unauthenticated("bad timestamp")
+	}
+	now := h.now()
+	if d := now.Sub(time.UnixMilli(ms)); d > h.window || d < -h.window {
+		return unauthenticated("timestamp outside window")
+	}
+	msg := StringToSign(r.Method, r.URL.Path, ts, nonce, body)
+	ok := false
+	for _, s := range h.secrets {
+		if hmac.Equal([]byte(signature(s, msg)), []byte(sig)) {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return unauthenticated("bad signature")
+	}
+	if !h.remember(nonce, now) {
+		return unauthenticated("replayed nonce")
+	}
+	return nil
+}
+
+// remember 记录 nonce，窗口内重复出现时返回 false；顺带从队首清理过期条目（均摊 O(1)）。
+func (h *HMACAuth) remember(nonce string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for n < len(h.order) && now.After(h.order[n].exp) {
+		delete(h.nonces, h.order[n].nonce)
+		n++
+	}
+	if n > 0 {
+		h.order = append(h.order[:0], h.order[n:]...)
+	}
+	if _, seen := h.nonces[nonce]; seen {
+		return false
+	}
+	// 时间戳允许前后各偏差一个窗口，nonce 需保留两个窗口才能覆盖全部可接受的请求
+	exp := now.Add(2 * h.window)
+	if k := len(h.order); k > 0 && exp.Before(h.order[k-1].exp) {
+		exp = h.order[k-1].exp // 时钟回拨时保持队列有序，只会让该 nonce 多保留一会儿
+	}
+	h.nonces[nonce] = exp
+	h.order = append(h.order, nonceEntry{nonce: nonce, exp: exp})
+	return true
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BreakerState 熔断器状态。
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "CLOSED"    // 正常放行
+	BreakerOpen     BreakerState = "OPEN"      // 快速失败，不发出请求
+	BreakerHalfOpen BreakerState = "HALF_OPEN" // 冷却结束，仅放行一个探测请求
+)
+
+// BreakerPolicy 按 Server 地址熔断：连续 FailureThreshold 次网络错误或 5xx 后打开，
+// 经过 OpenTimeout 进入半开，探测成功则关闭，失败则重新打开。
+type BreakerPolicy struct {
+	FailureThreshold int           // 连续失败阈值，<=0 关闭熔断
+	OpenTimeout      time.Duration // 打开状态持续时间
+	// OnStateChange 状态变化回调（可选），用于日志与告警；在持锁外调用。
+	OnStateChange func(addr string, from, to BreakerState)
+}
+
+// DefaultBreakerPolicy 默认熔断策略：连续 5 次失败打开，30s 后半开探测。
+var DefaultBreakerPolicy = BreakerPolicy{FailureThreshold: 5, OpenTimeout: 30 * time.Second}
+
+// breaker 维护各 Server 地址的熔断状态。
+type breaker struct {
+	p   BreakerPolicy
+	mu  sync.Mutex
+	m   map[string]*circuit
+	now func() time.Time
+}
+
+type circuit struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(p BreakerPolicy) *breaker {
+	return &breaker{p: p, m: map[string]*circuit{}, now: time.Now}
+}
+
+// allow 判断是否放行发往 addr 的请求；打开或半开探测进行中时返回 ErrCircuitOpen。
+func (b *breaker) allow(addr string) error {
+	if b == nil || b.p.FailureThreshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	c := b.circuit(addr)
+	from := c.state
+	switch c.state {
+	case BreakerOpen:
+		if b.now().Sub(c.openedAt) < b.p.OpenTimeout {
+			b.mu.Unlock()
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, addr)
+		}
+		c.state, c.probing = BreakerHalfOpen, true
+	case BreakerHalfOpen:
+		if c.probing {
+			b.mu.Unlock()
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, addr)
+		}
+		c.probing = true
+	}
+	to := c.state
+	b.mu.Unlock()
+	b.notify(addr, from, to)
+	return nil
+}
+
+// record 记录一次放行请求的结果：err 为 nil 视为成功；网络错误与 5xx 计为失败；
+// 其它错误（业务失败、4xx）说明 Server 可达，半开时据此关闭，关闭时不改变计数。
+func (b *breaker) record(addr string, err error) {
+	if b == nil || b.p.FailureThreshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	c := b.circuit(addr)
+	from := c.state
+	c.probing = false
+	switch {
+	case err == nil:
+		c.state, c.failures = BreakerClosed, 0
+	case serverFault(err):
+		c.failures++
+		if c.state == BreakerHalfOpen || c.failures >= b.p.FailureThreshold {
+			c.state, c.openedAt = BreakerOpen, b.now()
+		}
+	case c.state == BreakerHalfOpen:
+		c.state, c.failures = BreakerClosed, 0
+	}
+	to := c.state
+	b.mu.Unlock()
+	b.notify(addr, from, to)
+}
+
+// release 放弃一次已放行的请求（调用方取消），不计入结果，半开时允许下一次探测。
+func (b *breaker) release(addr string) {
+	if b == nil || b.p.FailureThreshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.circuit(addr).probing = false
+	b.mu.Unlock()
+}
+
+// state 返回 addr 当前的熔断状态。
+func (b *breaker) state(addr string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.circuit(addr).state
+}
+
+func (b *breaker) circuit(addr string) *circuit {
+	c, ok := b.m[addr]
+	if !ok {
+		c = &circuit{state: BreakerClosed}
+		b.m[addr] = c
+	}
+	return c
+}
+
+func (b *breaker) notify(addr string, from, to BreakerState) {
+	if from != to && b.p.OnStateChange != nil {
+		b.p.OnStateChange(addr, from, to)
+	}
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultGzipThreshold WithGzip 未指定阈值时使用的默认值：请求体达到 1KB 才压缩。
+const DefaultGzipThreshold = 1 << 10
+
+// WithGzip 对达到 minBytes 的请求体启用 gzip 压缩（Content-Encoding: gzip），适合日志与状态批量上报；
+// minBytes<=0 时使用 DefaultGzipThreshold。Server 以 415 拒绝压缩请求时自动以未压缩方式重发，
+// 并在之后对该 Server 地址不再压缩；不识别 Content-Encoding 的 Server 常以 400/5xx 失败，
+// 因此每个地址首次出现响应体表明解码失败的 400、或幂等调用的 400/5xx 时也会以未压缩方式重发一次，
+// 成功则同样记住该地址不再压缩。状态、日志上报等非幂等调用遇到其它 400/5xx 不重发，避免 Server 重复处理。
+func WithGzip(minBytes int) ClientOption {
+	return func(h *httpServerAPI) {
+		if minBytes <= 0 {
+			minBytes = DefaultGzipThreshold
+		}
+		h.gzipMin = minBytes
+	}
+}
+
+// gzipState 记录各 Server 地址对压缩请求的支持情况。
+type gzipState struct {
+	rejected sync.Map // addr -> struct{}：不支持压缩，之后发送未压缩请求
+	verified sync.Map // addr -> struct{}：已确认支持压缩或已探测过，400/5xx 不再触发回退
+}
+
+// shouldGzip 判断发往 addr 的 n 字节请求体是否压缩。
+func (h *httpServerAPI) shouldGzip(addr string, n int) bool {
+	if h.gzipMin <= 0 || n < h.gzipMin {
+		return false
+	}
+	_, no := h.gz.rejected.Load(addr)
+	return !no
+}
+
+// gzipFallback 处理压缩请求的结果 err，必要时以未压缩方式重发一次并返回重发结果。
+func (h *httpServerAPI) gzipFallback(ctx context.Context, c Call, n int, method, u, ctype string, body []byte, out any, err error) error {
+	addr := hostOf(u)
+	var se *StatusError
+	var be *BusinessError
+	switch {
+	case err == nil, errors.As(err, &be):
+		// Server 解析了压缩请求体
+		h.gz.verified.Store(addr, struct{}{})
+		return err
+	case !errors.As(err, &se) || !se.Compressed:
+		return err
+	case se.StatusCode == http.StatusUnsupportedMediaType:
+		h.gz.rejected.Store(addr, struct{}{})
+		return h.attempt(ctx, c, n, method, u, ctype, body, false, out)
+	case se.StatusCode == http.StatusBadRequest && (undecodable(se.Body) || h.idem[c]),
+		se.StatusCode >= http.StatusInternalServerError && h.idem[c]:
+		if _, probed := h.gz.verified.LoadOrStore(addr, struct{}{}); probed {
+			return err
+		}
+		plain := h.attempt(ctx, c, n, method, u, ctype, body, false, out)
+		if plain == nil {
+			h.gz.rejected.Store(addr, struct{}{})
+		}
+		return plain
+	}
+	return err
+}
+
+// decodeFailureHints 400 响应体中表明请求体未能解码（因而未被处理）的特征，均为小写。
+var decodeFailureHints = []string{"json", "parse", "decod", "unrecognized", "invalid character", "malformed", "not readable", "gzip", "encoding"}
+
+// undecodable 判断 400 响应体是否表明 Server 未能解码请求体。
+func undecodable(body string) bool {
+	body = strings.ToLower(body)
+	for _, h := range decodeFailureHints {
+		if strings.Contains(body, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipBytes 压缩 b。
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHTTPServerAPI_Gzip(t *testing.T) {
+	Convey("large bodies should be gzip-compressed above the threshold", t, func() {
+		var mu sync.Mutex
+		var encodings []string
+		var logs []WorkerLogReportReq
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var rd io.Reader = r.Body
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				zr, err := gzip.NewReader(r.Body)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				rd = zr
+			}
+			var req WorkerLogReportReq
+			_ = json.NewDecoder(rd).Decode(&req)
+			mu.Lock()
+			encodings = append(encodings, r.Header.Get("Content-Encoding"))
+			logs = append(logs, req)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(CommonResp[any]{Success: true})
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		var infos []CallInfo
+		api := NewHTTPServerAPI(WithGzip(512), WithResponseHook(func(ctx context.Context, info CallInfo) { infos = append(infos, info) }))
+
+		big := WorkerLogReportReq{WorkerAddress: "w", InstanceLogContents: []InstanceLogContent{{InstanceID: 1, LogContent: strings.Repeat("stack trace line\n", 200)}}}
+		So(api.ReportLog(context.Background(), host, big), ShouldBeNil)
+		So(api.ReportLog(context.Background(), host, WorkerLogReportReq{WorkerAddress: "w"}), ShouldBeNil)
+		So(encodings, ShouldResemble, []string{"gzip", ""})
+		So(logs[0].InstanceLogContents[0].LogContent, ShouldEqual, big.InstanceLogContents[0].LogContent)
+		So(infos[0].Compressed, ShouldBeTrue)
+		So(infos[0].BytesSent, ShouldBeLessThan, 512)
+	})
+
+	Convey("servers rejecting gzip with 415 should get plain bodies from then on", t, func() {
+		var mu sync.Mutex
+		var encodings []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			encodings = append(encodings, r.Header.Get("Content-Encoding"))
+			mu.Unlock()
+			if r.Header.Get("Content-Encoding") != "" {
+				http.Error(w, "unsupported", http.StatusUnsupportedMediaType)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CommonResp[any]{Success: true})
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		api := NewHTTPServerAPI(WithGzip(1))
+
+		So(api.ReportLog(context.Background(), host, WorkerLogReportReq{WorkerAddress: "w"}), ShouldBeNil)
+		So(api.ReportLog(context.Background(), host, WorkerLogReportReq{WorkerAddress: "w"}), ShouldBeNil)
+		So(encodings, ShouldResemble, []string{"gzip", "", ""})
+	})
+	Convey("servers failing gzip bodies with 400 get one plain retry and plain bodies afterwards", t, func() {
+		var mu sync.Mutex
+		var encodings []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			encodings = append(encodings, r.Header.Get("Content-Encoding"))
+			mu.Unlock()
+			var req WorkerLogReportReq
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil { // 不识别 Content-Encoding，按 JSON 解析失败
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CommonResp[any]{Success: true})
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		api := NewHTTPServerAPI(WithGzip(1))
+
+		So(api.ReportLog(context.Background(), host, WorkerLogReportReq{WorkerAddress: "w"}), ShouldBeNil)
+		So(api.ReportLog(context.Background(), host, WorkerLogReportReq{WorkerAddress: "w"}), ShouldBeNil)
+		So(encodings, ShouldResemble, []string{"gzip", "", ""})
+	})
+
+	Convey("non-idempotent reports are not resent after a 5xx or an opaque 400", t, func() {
+		var mu sync.Mutex
+		var encodings []string
+		status := http.StatusInternalServerError
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			encodings = append(encodings, r.Header.Get("Content-Encoding"))
+			mu.Unlock()
+			http.Error(w, "rejected", status)
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		api := NewHTTPServerAPI(WithGzip(1), WithCircuitBreaker(BreakerPolicy{FailureThreshold: 100}))
+
+		So(api.ReportLog(context.Background(), host, WorkerLogReportReq{WorkerAddress: "w"}), ShouldNotBeNil)
+		status = http.StatusBadRequest
+		So(api.ReportInstanceStatus(context.Background(), host, TaskTrackerReportInstanceStatusReq{InstanceID: 1}), ShouldNotBeNil)
+		So(encodings, ShouldResemble, []string{"gzip", "gzip"})
+	})
+
+	Convey("an idempotent 5xx that also fails uncompressed is probed only once per address", t, func() {
+		var mu sync.Mutex
+		var encodings []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			encodings = append(encodings, r.Header.Get("Content-Encoding"))
+			mu.Unlock()
+			http.Error(w, "down", http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		api := NewHTTPServerAPI(WithGzip(1), WithCircuitBreaker(BreakerPolicy{FailureThreshold: 100}),
+			WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+		So(api.Heartbeat(context.Background(), host, WorkerHeartbeat{WorkerAddress: "w"}), ShouldNotBeNil)
+		So(api.Heartbeat(context.Background(), host, WorkerHeartbeat{WorkerAddress: "w"}), ShouldNotBeNil)
+		So(encodings, ShouldResemble, []string{"gzip", "", "gzip"})
+	})
+}
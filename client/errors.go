@@ -0,0 +1,73 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrCircuitOpen Server 地址的熔断器处于打开状态，本次调用未发出请求。
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// ErrTLSUnsupported WithTLSConfig 与非 *http.Transport 的 RoundTripper 同时使用，TLS 配置无法生效，
+// 为避免静默丢弃 CA 与客户端证书，所有调用都直接返回该错误。
+var ErrTLSUnsupported = errors.New("client: WithTLSConfig requires an *http.Transport; configure TLS on the custom RoundTripper instead")
+
+// NetworkError 请求未得到 HTTP 响应（连接失败、超时、TLS 错误等），Err 为原始错误。
+type NetworkError struct {
+	Call Call
+	URL  string
+	Err  error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("%s %s: network error: %v", e.Call, e.URL, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// StatusError Server 返回了非 2xx 状态码，Body 为响应体（截断后）。
+type StatusError struct {
+	Call       Call
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+	Compressed bool // 请求体是否以 gzip 发送
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s => %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// BusinessError Server 正常响应但 success=false，Message 为 Server 给出的原因。
+type BusinessError struct {
+	Call    Call
+	Message string
+}
+
+func (e *BusinessError) Error() string { return fmt.Sprintf("%s failed: %s", e.Call, e.Message) }
+
+// IsRetryable 判断错误是否值得重试：网络错误、5xx、408 与 429 可重试；
+// 业务失败（success=false）、其它 4xx 与熔断打开不重试。
+func IsRetryable(err error) bool {
+	var ne *NetworkError
+	if errors.As(err, &ne) {
+		return true
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500 || se.StatusCode == http.StatusRequestTimeout || se.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// serverFault 判断错误是否计入熔断：只有网络错误与 5xx 说明 Server 不可用。
+func serverFault(err error) bool {
+	var ne *NetworkError
+	if errors.As(err, &ne) {
+		return true
+	}
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode >= 500
+}
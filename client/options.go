@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// Call 标识一次 ServerAPI 调用的类型，用于按调用配置超时与埋点。
+type Call string
+
+const (
+	CallAssert       Call = "assert"
+	CallAcquire      Call = "acquire"
+	CallHeartbeat    Call = "heartbeat"
+	CallReportStatus Call = "reportInstanceStatus"
+	CallReportLog    Call = "reportLog"
+)
+
+// DefaultTimeout 单次调用默认超时。
+const DefaultTimeout = 8 * time.Second
+
+// CallInfo 一次 HTTP 调用的结果，供响应 Hook 做埋点（耗时、状态码、错误）。
+type CallInfo struct {
+	Call       Call
+	Method     string
+	URL        string
+	Attempt    int // 第几次尝试，从 1 开始
+	Status     int // 未收到响应时为 0
+	Err        error
+	Duration   time.Duration
+	BytesSent  int  // 实际发送的请求体字节数（压缩后）
+	Compressed bool // 请求体是否以 gzip 发送
+}
+
+// ClientOption NewHTTPServerAPI 的可选项。
+type ClientOption func(*httpServerAPI)
+
+// WithHTTPClient 使用调用方提供的 http.Client（连接池、代理、Timeout 等均以其为准）。
+func WithHTTPClient(hc *http.Client) ClientOption { return func(h *httpServerAPI) { h.hc = hc } }
+
+// WithTransport 替换底层 RoundTripper，例如接入链路追踪或自定义连接池。
+// 说明：与 WithTLSConfig 同时使用时 rt 必须为 *http.Transport，否则所有调用返回 ErrTLSUnsupported；其它实现请自行配置 TLS。
+func WithTransport(rt http.RoundTripper) ClientOption { return func(h *httpServerAPI) { h.rt = rt } }
+
+// WithTLSConfig 使用 HTTPS 访问 Server，cfg 可配置自定义 CA 与客户端证书（双向 TLS），
+// 可由 tlsutil.Reloader.ClientConfig 生成以支持证书热更新。
+func WithTLSConfig(cfg *tls.Config) ClientOption {
+	return func(h *httpServerAPI) { h.tlsCfg, h.scheme = cfg, "https" }
+}
+
+// WithScheme 指定访问 Server 的协议（http 或 https），默认 http，配置 TLS 时为 https。
+// 说明：地址本身带有 scheme（如 https://host:port）时以地址为准。
+func WithScheme(scheme string) ClientOption { return func(h *httpServerAPI) { h.scheme = scheme } }
+
+// WithRetryPolicy 设置幂等调用（assert、acquire、heartbeat）的重试策略，默认 DefaultRetryPolicy；
+// MaxAttempts<=1 关闭重试。
+func WithRetryPolicy(p RetryPolicy) ClientOption { return func(h *httpServerAPI) { h.retry = p } }
+
+// WithCircuitBreaker 设置按 Server 地址的熔断策略，默认 DefaultBreakerPolicy；FailureThreshold<=0 关闭熔断。
+func WithCircuitBreaker(p BreakerPolicy) ClientOption {
+	return func(h *httpServerAPI) { h.brk = newBreaker(p) }
+}
+
+// WithTimeout 设置所有调用的默认单次尝试超时（<=0 表示不设超时，仅受 ctx 控制）。
+func WithTimeout(d time.Duration) ClientOption { return func(h *httpServerAPI) { h.timeout = d } }
+
+// WithCallTimeout 为指定调用单独设置超时，例如心跳取短、日志上报取长。
+func WithCallTimeout(c Call, d time.Duration) ClientOption {
+	return func(h *httpServerAPI) { h.callTimeout[c] = d }
+}
+
+// WithClientVersion 设置客户端版本，用于默认 User-Agent：powerjob-client-go/<version>。
+func WithClientVersion(v string) ClientOption { return func(h *httpServerAPI) { h.version = v } }
+
+// WithUserAgent 覆盖默认 User-Agent。
+func WithUserAgent(ua string) ClientOption { return func(h *httpServerAPI) { h.userAgent = ua } }
+
+// WithHeader 为所有请求追加默认请求头（如租户、网关鉴权头）。
+func WithHeader(key, value string) ClientOption {
+	return func(h *httpServerAPI) { h.headers.Set(key, value) }
+}
+
+// WithRequestHook 在请求发出前回调，可用于注入追踪头或签名；hook 不应读取请求体。
+func WithRequestHook(fn func(ctx context.Context, c Call, r *http.Request)) ClientOption {
+	return func(h *httpServerAPI) { h.onRequest = append(h.onRequest, fn) }
+}
+
+// WithResponseHook 在每次调用结束后回调（无论成功失败），用于记录耗时、状态码等指标。
+func WithResponseHook(fn func(ctx context.Context, info CallInfo)) ClientOption {
+	return func(h *httpServerAPI) { h.onResponse = append(h.onResponse, fn) }
+}
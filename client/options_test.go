@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// roundTripFunc 便于在测试中替换 RoundTripper。
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestHTTPServerAPI_Options(t *testing.T) {
+	Convey("headers, user agent and hooks should be applied to every call", t, func() {
+		var mu sync.Mutex
+		var got http.Header
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			got = r.Header.Clone()
+			mu.Unlock()
+			if r.URL.Path == "/server/reportLog" {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CommonResp[int64]{Success: true, Data: 1})
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+
+		var calls []CallInfo
+		api := NewHTTPServerAPI(
+			WithClientVersion("1.2.3"),
+			WithHeader("X-Tenant", "t1"),
+			WithRequestHook(func(ctx context.Context, c Call, r *http.Request) { r.Header.Set("X-Call", string(c)) }),
+			WithResponseHook(func(ctx context.Context, info CallInfo) { calls = append(calls, info) }),
+		)
+		_, err := api.AssertApp(context.Background(), host, "demo")
+		So(err, ShouldBeNil)
+		So(got.Get("User-Agent"), ShouldEqual, "powerjob-client-go/1.2.3")
+		So(got.Get("X-Tenant"), ShouldEqual, "t1")
+		So(got.Get("X-Call"), ShouldEqual, "assert")
+
+		err = api.ReportLog(context.Background(), host, WorkerLogReportReq{})
+		So(err, ShouldNotBeNil)
+		So(len(calls), ShouldEqual, 2)
+		So(calls[0].Call, ShouldEqual, CallAssert)
+		So(calls[0].Status, ShouldEqual, http.StatusOK)
+		So(calls[0].Err, ShouldBeNil)
+		So(calls[1].Call, ShouldEqual, CallReportLog)
+		So(calls[1].Method, ShouldEqual, http.MethodPost)
+		So(calls[1].Status, ShouldEqual, http.StatusBadGateway)
+		So(calls[1].Err, ShouldNotBeNil)
+		So(calls[1].BytesSent, ShouldBeGreaterThan, 0)
+
+		Convey("WithUserAgent overrides the default", func() {
+			_, err := NewHTTPServerAPI(WithClientVersion("1.2.3"), WithUserAgent("my-agent")).AssertApp(context.Background(), host, "demo")
+			So(err, ShouldBeNil)
+			So(got.Get("User-Agent"), ShouldEqual, "my-agent")
+		})
+	})
+
+	Convey("per-call timeouts should bound only the configured call", t, func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(200 * time.Millisecond):
+			case <-r.Context().Done():
+			}
+			_ = json.NewEncoder(w).Encode(CommonResp[int64]{Success: true, Data: 1})
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		api := NewHTTPServerAPI(WithCallTimeout(CallHeartbeat, 20*time.Millisecond))
+
+		err := api.Heartbeat(context.Background(), host, WorkerHeartbeat{})
+		So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		_, err = api.AssertApp(context.Background(), host, "demo")
+		So(err, ShouldBeNil)
+	})
+
+	Convey("custom transports should carry every request", t, func() {
+		var n int
+		rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			n++
+			rec := httptest.NewRecorder()
+			_ = json.NewEncoder(rec).Encode(CommonResp[string]{Success: true, Data: "10.0.0.1:10010"})
+			return rec.Result(), nil
+		})
+		addr, err := NewHTTPServerAPI(WithTransport(rt)).Acquire(context.Background(), "server:7700", 1, "", "")
+		So(err, ShouldBeNil)
+		So(addr, ShouldEqual, "10.0.0.1:10010")
+		So(n, ShouldEqual, 1)
+
+		n = 0
+		_, err = NewHTTPServerAPI(WithHTTPClient(&http.Client{Transport: rt})).Acquire(context.Background(), "server:7700", 1, "", "")
+		So(err, ShouldBeNil)
+		So(n, ShouldEqual, 1)
+	})
+	Convey("TLS with a RoundTripper that cannot carry it should fail instead of dropping TLS", t, func() {
+		called := false
+		rt := roundTripFunc(func(r *http.Request) (*http.Response, error) { called = true; return nil, errors.New("unreachable") })
+		_, err := NewHTTPServerAPI(WithTransport(rt), WithTLSConfig(&tls.Config{})).Acquire(context.Background(), "server:7700", 1, "", "")
+		So(errors.Is(err, ErrTLSUnsupported), ShouldBeTrue)
+		So(called, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy 幂等调用（assert、acquire、heartbeat）的重试策略，按指数退避并附加随机抖动。
+// 状态与日志上报不是幂等的，不会自动重试，由上报调度器在下个周期补报。
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大尝试次数（含首次），<=1 表示不重试
+	InitialBackoff time.Duration // 首次重试前的等待
+	MaxBackoff     time.Duration // 单次等待上限
+	Multiplier     float64       // 退避倍数，<1 时按 2 处理
+	Jitter         float64       // 抖动比例 0~1，等待时间在 [d*(1-Jitter), d] 之间
+}
+
+// DefaultRetryPolicy 默认重试策略：最多 3 次，200ms 起按 2 倍退避，上限 2s。
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, InitialBackoff: 200 * time.Millisecond, MaxBackoff: 2 * time.Second, Multiplier: 2, Jitter: 0.2}
+
+// idempotentCalls 默认可安全重试的调用，Transport.Idempotent 可追加。
+var idempotentCalls = []Call{CallAssert, CallAcquire, CallHeartbeat}
+
+// attempts 返回调用的最大尝试次数，非幂等调用只尝试一次。
+func (p RetryPolicy) attempts(idempotent bool) int {
+	if !idempotent || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff 返回第 n 次重试（从 1 开始）前的等待时间。
+func (p RetryPolicy) backoff(n int) time.Duration {
+	m := p.Multiplier
+	if m < 1 {
+		m = 2
+	}
+	d := float64(p.InitialBackoff)
+	for i := 1; i < n; i++ {
+		d *= m
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			d = float64(p.MaxBackoff)
+			break
+		}
+	}
+	if p.Jitter > 0 {
+		d -= d * min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// sleep 等待 d，ctx 结束时提前返回 false。
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
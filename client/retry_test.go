@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fastRetry 测试用重试策略，避免真实退避拖慢用例。
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+func TestHTTPServerAPI_Retry(t *testing.T) {
+	Convey("idempotent calls should be retried on 5xx, others should not", t, func() {
+		var hits atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hits.Add(1) < 3 {
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CommonResp[int64]{Success: true, Data: 5})
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		api := NewHTTPServerAPI(WithRetryPolicy(fastRetry))
+
+		appID, err := api.AssertApp(context.Background(), host, "demo")
+		So(err, ShouldBeNil)
+		So(appID, ShouldEqual, 5)
+		So(hits.Load(), ShouldEqual, 3)
+
+		hits.Store(0)
+		err = api.ReportInstanceStatus(context.Background(), host, TaskTrackerReportInstanceStatusReq{})
+		var se *StatusError
+		So(errors.As(err, &se), ShouldBeTrue)
+		So(se.StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+		So(hits.Load(), ShouldEqual, 1)
+	})
+
+	Convey("business failures and 4xx should not be retried", t, func() {
+		var hits atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			if r.URL.Path == "/server/acquire" {
+				http.Error(w, "bad", http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CommonResp[int64]{Success: false, Message: "app not found"})
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		api := NewHTTPServerAPI(WithRetryPolicy(fastRetry))
+
+		_, err := api.AssertApp(context.Background(), host, "none")
+		var be *BusinessError
+		So(errors.As(err, &be), ShouldBeTrue)
+		So(be.Message, ShouldEqual, "app not found")
+		So(IsRetryable(err), ShouldBeFalse)
+
+		_, err = api.Acquire(context.Background(), host, 1, "", "")
+		var se *StatusError
+		So(errors.As(err, &se), ShouldBeTrue)
+		So(hits.Load(), ShouldEqual, 2)
+	})
+
+	Convey("network failures should surface as NetworkError", t, func() {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		host := ts.Listener.Addr().String()
+		ts.Close()
+		_, err := NewHTTPServerAPI(WithRetryPolicy(fastRetry)).AssertApp(context.Background(), host, "demo")
+		var ne *NetworkError
+		So(errors.As(err, &ne), ShouldBeTrue)
+		So(IsRetryable(err), ShouldBeTrue)
+	})
+
+	Convey("backoff should grow and stay within the cap", t, func() {
+		p := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+		So(p.backoff(1), ShouldEqual, 100*time.Millisecond)
+		So(p.backoff(2), ShouldEqual, 200*time.Millisecond)
+		So(p.backoff(3), ShouldEqual, 300*time.Millisecond)
+		So(p.backoff(9), ShouldEqual, 300*time.Millisecond)
+		So(p.attempts(false), ShouldEqual, 1)
+		So(p.attempts(true), ShouldEqual, 5)
+	})
+}
+
+func TestHTTPServerAPI_CircuitBreaker(t *testing.T) {
+	Convey("the breaker should open after repeated failures and recover via a half-open probe", t, func() {
+		var hits atomic.Int32
+		var healthy atomic.Bool
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			if !healthy.Load() {
+				http.Error(w, "down", http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		var changes []BreakerState
+		api := NewHTTPServerAPI(WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithCircuitBreaker(BreakerPolicy{
+			FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond,
+			OnStateChange: func(addr string, from, to BreakerState) { changes = append(changes, to) },
+		}))
+		ctx := context.Background()
+
+		So(api.Heartbeat(ctx, host, WorkerHeartbeat{}), ShouldNotBeNil)
+		So(api.Heartbeat(ctx, host, WorkerHeartbeat{}), ShouldNotBeNil)
+		err := api.Heartbeat(ctx, host, WorkerHeartbeat{})
+		So(errors.Is(err, ErrCircuitOpen), ShouldBeTrue)
+		So(hits.Load(), ShouldEqual, 2)
+
+		Convey("a failed probe reopens the breaker", func() {
+			time.Sleep(60 * time.Millisecond)
+			So(api.Heartbeat(ctx, host, WorkerHeartbeat{}), ShouldNotBeNil)
+			So(hits.Load(), ShouldEqual, 3)
+			So(errors.Is(api.Heartbeat(ctx, host, WorkerHeartbeat{}), ErrCircuitOpen), ShouldBeTrue)
+			So(changes, ShouldResemble, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen})
+		})
+
+		Convey("a successful probe closes the breaker", func() {
+			healthy.Store(true)
+			time.Sleep(60 * time.Millisecond)
+			So(api.Heartbeat(ctx, host, WorkerHeartbeat{}), ShouldBeNil)
+			So(api.Heartbeat(ctx, host, WorkerHeartbeat{}), ShouldBeNil)
+			So(changes, ShouldResemble, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed})
+		})
+	})
+
+	Convey("half-open should admit a single probe at a time", t, func() {
+		b := newBreaker(BreakerPolicy{FailureThreshold: 1, OpenTimeout: time.Second})
+		now := time.Now()
+		b.now = func() time.Time { return now }
+		b.record("s:1", &NetworkError{Err: errors.New("refused")})
+		So(b.state("s:1"), ShouldEqual, BreakerOpen)
+		So(b.allow("s:2"), ShouldBeNil)
+
+		now = now.Add(2 * time.Second)
+		So(b.allow("s:1"), ShouldBeNil)
+		So(errors.Is(b.allow("s:1"), ErrCircuitOpen), ShouldBeTrue)
+		b.release("s:1")
+		So(b.allow("s:1"), ShouldBeNil)
+		b.record("s:1", &BusinessError{Message: "no"})
+		So(b.state("s:1"), ShouldEqual, BreakerClosed)
+	})
+}
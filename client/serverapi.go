@@ -3,11 +3,13 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/mengeric/powerjob-client-go/logging"
@@ -24,22 +26,104 @@ type ServerAPI interface {
 }
 
 // httpServerAPI 实现 ServerAPI。
-type httpServerAPI struct{ hc *http.Client }
+type httpServerAPI struct {
+	hc          *http.Client
+	rt          http.RoundTripper
+	tlsCfg      *tls.Config
+	scheme      string
+	timeout     time.Duration
+	callTimeout map[Call]time.Duration
+	version     string
+	userAgent   string
+	headers     http.Header
+	retry       RetryPolicy
+	idem        map[Call]bool
+	brk         *breaker
+	gzipMin     int
+	gz          gzipState
+	onRequest   []func(ctx context.Context, c Call, r *http.Request)
+	onResponse  []func(ctx context.Context, info CallInfo)
+	initErr     error // 构造期发现的配置错误，所有调用直接返回
+}
 
 // NewHTTPServerAPI 构造 HTTP 实现。
-func NewHTTPServerAPI() ServerAPI { return &httpServerAPI{hc: &http.Client{Timeout: 8 * time.Second}} }
+// 参数：opts 可配置 http.Client/RoundTripper、TLS、默认及按调用的超时、默认请求头与 User-Agent、埋点 Hook、重试与熔断策略、请求压缩。
+// 错误：网络错误为 *NetworkError，非 2xx 为 *StatusError，success=false 为 *BusinessError，熔断打开时包装 ErrCircuitOpen。
+func NewHTTPServerAPI(opts ...ClientOption) ServerAPI { return newHTTPServerAPI(opts...) }
+
+func newHTTPServerAPI(opts ...ClientOption) *httpServerAPI {
+	h := &httpServerAPI{
+		hc:          &http.Client{},
+		scheme:      "http",
+		timeout:     DefaultTimeout,
+		callTimeout: map[Call]time.Duration{},
+		headers:     http.Header{},
+		retry:       DefaultRetryPolicy,
+		idem:        map[Call]bool{},
+		brk:         newBreaker(DefaultBreakerPolicy),
+	}
+	for _, c := range idempotentCalls {
+		h.idem[c] = true
+	}
+	for _, fn := range opts {
+		fn(h)
+	}
+	if h.rt != nil {
+		hc := *h.hc
+		hc.Transport = h.rt
+		h.hc = &hc
+	}
+	if h.tlsCfg != nil {
+		h.applyTLS()
+	}
+	if h.userAgent == "" {
+		h.userAgent = "powerjob-client-go"
+		if h.version != "" {
+			h.userAgent += "/" + h.version
+		}
+	}
+	return h
+}
+
+// applyTLS 将 TLS 配置写入 *http.Transport（复制后修改，不影响调用方传入的实例）；
+// 其它 RoundTripper 无法注入 TLS 配置，记录 initErr 使调用失败而不是丢弃 TLS 设置。
+func (h *httpServerAPI) applyTLS() {
+	var tr *http.Transport
+	switch t := h.hc.Transport.(type) {
+	case nil:
+		tr = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		tr = t.Clone()
+	default:
+		h.initErr = fmt.Errorf("%w (got %T)", ErrTLSUnsupported, t)
+		logging.L().Errorf(context.Background(), "%v", h.initErr)
+		return
+	}
+	tr.TLSClientConfig = h.tlsCfg
+	hc := *h.hc
+	hc.Transport = tr
+	h.hc = &hc
+}
+
+// url 拼接请求地址：addr 为 host:port 时补全 scheme，已带 scheme 时原样使用。
+func (h *httpServerAPI) url(addr, path string) string {
+	if strings.Contains(addr, "://") {
+		return strings.TrimSuffix(addr, "/") + path
+	}
+	return h.scheme + "://" + addr + path
+}
 
 // AssertApp 发起 /server/assert 校验应用是否已注册。
 // 参数：bootstrapHost 形如 127.0.0.1:7700，appName 应用名。
 // 返回：appID，或错误。
 func (h *httpServerAPI) AssertApp(ctx context.Context, bootstrapHost, appName string) (int64, error) {
-	u := fmt.Sprintf("http://%s/server/assert?appName=%s", bootstrapHost, url.QueryEscape(appName))
+	u := h.url(bootstrapHost, "/server/assert?appName="+url.QueryEscape(appName))
 	var resp CommonResp[int64]
-	if err := h.get(ctx, u, &resp); err != nil {
+	if err := h.get(ctx, CallAssert, u, &resp); err != nil {
 		return 0, err
 	}
 	if !resp.Success {
-		return 0, fmt.Errorf("assert app failed: %s", resp.Message)
+		return 0, &BusinessError{Call: CallAssert, Message: resp.Message}
 	}
 	return resp.Data, nil
 }
@@ -55,68 +139,163 @@ func (h *httpServerAPI) Acquire(ctx context.Context, base string, appID int64, c
 	if clientVersion != "" {
 		v.Set("clientVersion", clientVersion)
 	}
-	u := fmt.Sprintf("http://%s/server/acquire?%s", base, v.Encode())
+	u := h.url(base, "/server/acquire?"+v.Encode())
 	var resp CommonResp[string]
-	if err := h.get(ctx, u, &resp); err != nil {
+	if err := h.get(ctx, CallAcquire, u, &resp); err != nil {
 		return "", err
 	}
 	if !resp.Success {
-		return "", fmt.Errorf("acquire failed: %s", resp.Message)
+		return "", &BusinessError{Call: CallAcquire, Message: resp.Message}
 	}
 	return resp.Data, nil
 }
 
 // Heartbeat 上报心跳。
 func (h *httpServerAPI) Heartbeat(ctx context.Context, serverAddr string, hb WorkerHeartbeat) error {
-	u := fmt.Sprintf("http://%s/server/workerHeartbeat", serverAddr)
-	return h.post(ctx, u, hb, nil)
+	u := h.url(serverAddr, "/server/workerHeartbeat")
+	return h.post(ctx, CallHeartbeat, u, hb)
 }
 
 // ReportInstanceStatus 上报实例状态。
 func (h *httpServerAPI) ReportInstanceStatus(ctx context.Context, serverAddr string, req TaskTrackerReportInstanceStatusReq) error {
-	u := fmt.Sprintf("http://%s/server/reportInstanceStatus", serverAddr)
-	return h.post(ctx, u, req, nil)
+	u := h.url(serverAddr, "/server/reportInstanceStatus")
+	return h.post(ctx, CallReportStatus, u, req)
 }
 
 // ReportLog 上报日志。
 func (h *httpServerAPI) ReportLog(ctx context.Context, serverAddr string, req WorkerLogReportReq) error {
-	u := fmt.Sprintf("http://%s/server/reportLog", serverAddr)
-	return h.post(ctx, u, req, nil)
+	u := h.url(serverAddr, "/server/reportLog")
+	return h.post(ctx, CallReportLog, u, req)
 }
 
 // get 执行 GET 请求并解码 JSON。
-func (h *httpServerAPI) get(ctx context.Context, url string, out any) error {
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	res, err := h.hc.Do(req)
+func (h *httpServerAPI) get(ctx context.Context, c Call, u string, out any) error {
+	return h.do(ctx, c, http.MethodGet, u, "", nil, out)
+}
+
+// post 执行 POST 请求并解码 CommonResp：success=false 返回 *BusinessError（附 Server 消息）。
+// 说明：响应体为空时视为成功，兼容只返回状态码的 Server。
+func (h *httpServerAPI) post(ctx context.Context, c Call, u string, body any) error {
+	b, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
-	if res.StatusCode/100 != 2 {
-		b, _ := io.ReadAll(res.Body)
-		return fmt.Errorf("GET %s => %d: %s", url, res.StatusCode, string(b))
+	resp := CommonResp[json.RawMessage]{Success: true}
+	if err := h.do(ctx, c, http.MethodPost, u, "application/json", b, &resp); err != nil {
+		return err
+	}
+	if !resp.Success {
+		return &BusinessError{Call: c, Message: resp.Message}
 	}
-	return json.NewDecoder(res.Body).Decode(out)
+	return nil
 }
 
-// post 执行 POST 请求并可选解码响应。
-func (h *httpServerAPI) post(ctx context.Context, u string, body any, out any) error {
-	b, _ := json.Marshal(body)
-	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
-	req.Header.Set("Content-Type", "application/json")
-	res, err := h.hc.Do(req)
+// do 发送请求：经熔断器放行后按重试策略尝试，仅对可重试错误（见 IsRetryable）退避重试。
+func (h *httpServerAPI) do(ctx context.Context, c Call, method, u, ctype string, body []byte, out any) error {
+	if h.initErr != nil {
+		return h.initErr
+	}
+	addr := hostOf(u)
+	var err error
+	for n, max := 1, h.retry.attempts(h.idem[c]); ; n++ {
+		if err = h.brk.allow(addr); err != nil {
+			return err
+		}
+		gz := h.shouldGzip(addr, len(body))
+		err = h.attempt(ctx, c, n, method, u, ctype, body, gz, out)
+		if gz {
+			// Server 可能不支持压缩请求：必要时以未压缩方式重发并记住该地址
+			err = h.gzipFallback(ctx, c, n, method, u, ctype, body, out, err)
+		}
+		if ctx.Err() != nil {
+			h.brk.release(addr)
+			return err
+		}
+		h.brk.record(addr, err)
+		if err == nil || n >= max || !IsRetryable(err) || !sleep(ctx, h.retry.backoff(n)) {
+			return err
+		}
+	}
+}
+
+// attempt 执行一次请求：应用按调用超时、默认请求头与 Hook，gz 为 true 时压缩请求体，
+// 非 2xx 返回 *StatusError，out 非 nil 时解码 JSON（空响应体保持 out 不变）。
+func (h *httpServerAPI) attempt(ctx context.Context, c Call, n int, method, u, ctype string, body []byte, gz bool, out any) (err error) {
+	if d := h.timeoutOf(c); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	if gz {
+		if body, err = gzipBytes(body); err != nil {
+			return err
+		}
+	}
+	info := CallInfo{Call: c, Method: method, URL: u, Attempt: n, BytesSent: len(body), Compressed: gz}
+	start := time.Now()
+	defer func() {
+		info.Err, info.Duration = err, time.Since(start)
+		for _, fn := range h.onResponse {
+			fn(ctx, info)
+		}
+	}()
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
 	if err != nil {
 		return err
 	}
+	for k, vs := range h.headers {
+		req.Header[k] = append([]string(nil), vs...)
+	}
+	req.Header.Set("User-Agent", h.userAgent)
+	if ctype != "" {
+		req.Header.Set("Content-Type", ctype)
+	}
+	if gz {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for _, fn := range h.onRequest {
+		fn(ctx, c, req)
+	}
+	res, err := h.hc.Do(req)
+	if err != nil {
+		return &NetworkError{Call: c, URL: u, Err: err}
+	}
 	defer res.Body.Close()
+	info.Status = res.StatusCode
 	if res.StatusCode/100 != 2 {
-		rb, _ := io.ReadAll(res.Body)
-		return fmt.Errorf("POST %s => %d: %s", u, res.StatusCode, string(rb))
+		rb, _ := io.ReadAll(io.LimitReader(res.Body, maxErrBody))
+		return &StatusError{Call: c, Method: method, URL: u, StatusCode: res.StatusCode, Body: string(rb), Compressed: gz}
 	}
 	if out == nil {
 		return nil
 	}
-	return json.NewDecoder(res.Body).Decode(out)
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("%s %s: decode response: %w", method, u, err)
+	}
+	return nil
+}
+
+// maxErrBody 错误响应体最多读取的字节数。
+const maxErrBody = 4 << 10
+
+// hostOf 返回 URL 中的 host:port，作为熔断的 Server 地址维度。
+func hostOf(u string) string {
+	if p, err := url.Parse(u); err == nil && p.Host != "" {
+		return p.Host
+	}
+	return u
+}
+
+// timeoutOf 返回调用的超时：优先按调用配置，否则取默认值。
+func (h *httpServerAPI) timeoutOf(c Call) time.Duration {
+	if d, ok := h.callTimeout[c]; ok {
+		return d
+	}
+	return h.timeout
 }
 
 // SafeLogErr 打印但不打断流程。
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -38,3 +39,32 @@ func TestHTTPServerAPI_Errors(t *testing.T) {
 		So(err, ShouldNotBeNil)
 	})
 }
+
+func TestHTTPServerAPI_PostEnvelope(t *testing.T) {
+	Convey("POST calls should surface success=false as BusinessError", t, func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/server/reportLog", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(CommonResp[any]{Success: false, Message: "log rejected"})
+		})
+		mux.HandleFunc("/server/workerHeartbeat", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(CommonResp[any]{Success: true})
+		})
+		mux.HandleFunc("/server/reportInstanceStatus", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		api := NewHTTPServerAPI()
+
+		err := api.ReportLog(context.Background(), host, WorkerLogReportReq{})
+		var be *BusinessError
+		So(errors.As(err, &be), ShouldBeTrue)
+		So(be.Call, ShouldEqual, CallReportLog)
+		So(be.Message, ShouldEqual, "log rejected")
+
+		So(api.Heartbeat(context.Background(), host, WorkerHeartbeat{}), ShouldBeNil)
+		// 空响应体视为成功
+		So(api.ReportInstanceStatus(context.Background(), host, TaskTrackerReportInstanceStatusReq{}), ShouldBeNil)
+	})
+}
@@ -36,3 +36,26 @@ func TestHTTPServerAPI_Basic(t *testing.T) {
 		So(addr, ShouldEqual, "127.0.0.1:10010")
 	})
 }
+
+func TestHTTPServerAPI_TLS(t *testing.T) {
+	Convey("WithTLSConfig should call the server over https", t, func() {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(CommonResp[int64]{Success: true, Data: 9})
+		}))
+		defer ts.Close()
+		host := ts.Listener.Addr().String()
+		cfg := ts.Client().Transport.(*http.Transport).TLSClientConfig
+
+		appID, err := NewHTTPServerAPI(WithTLSConfig(cfg)).AssertApp(context.Background(), host, "demo")
+		So(err, ShouldBeNil)
+		So(appID, ShouldEqual, 9)
+
+		_, err = NewHTTPServerAPI().AssertApp(context.Background(), host, "demo")
+		So(err, ShouldNotBeNil)
+
+		// 地址自带 scheme 时以地址为准
+		appID, err = NewHTTPServerAPI(WithTLSConfig(cfg), WithScheme("http")).AssertApp(context.Background(), ts.URL, "demo")
+		So(err, ShouldBeNil)
+		So(appID, ShouldEqual, 9)
+	})
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Transport 导出与 ServerAPI 相同的 HTTP 传输（超时、重试、熔断、压缩、请求头与 Hook），
+// 供 openapi 等访问 PowerJob-Server 其它接口的包复用。
+// 说明：Transport 只负责发送请求与解码 JSON，success=false 的判断由调用方按各自的响应结构处理。
+type Transport struct {
+	h *httpServerAPI
+}
+
+// NewTransport 构造传输，opts 与 NewHTTPServerAPI 相同。
+func NewTransport(opts ...ClientOption) *Transport {
+	return &Transport{h: newHTTPServerAPI(opts...)}
+}
+
+// Idempotent 声明可安全重试的调用，按 RetryPolicy 重试；默认仅 assert、acquire、heartbeat。
+// 需在发起请求前调用。
+func (t *Transport) Idempotent(calls ...Call) {
+	for _, c := range calls {
+		t.h.idem[c] = true
+	}
+}
+
+// URL 拼接请求地址：addr 为 host:port 时补全 scheme，已带 scheme 时原样使用。
+func (t *Transport) URL(addr, path string) string { return t.h.url(addr, path) }
+
+// Get 发送 GET 请求，out 非 nil 时解码 JSON 响应。
+func (t *Transport) Get(ctx context.Context, c Call, u string, out any) error {
+	return t.h.do(ctx, c, http.MethodGet, u, "", nil, out)
+}
+
+// PostJSON 以 JSON 请求体发送 POST 请求，out 非 nil 时解码 JSON 响应。
+func (t *Transport) PostJSON(ctx context.Context, c Call, u string, in, out any) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return t.h.do(ctx, c, http.MethodPost, u, "application/json", b, out)
+}
+
+// PostForm 以表单请求体（application/x-www-form-urlencoded）发送 POST 请求，out 非 nil 时解码 JSON 响应。
+func (t *Transport) PostForm(ctx context.Context, c Call, u string, form url.Values, out any) error {
+	return t.h.do(ctx, c, http.MethodPost, u, "application/x-www-form-urlencoded", []byte(form.Encode()), out)
+}
+
+// Invoke 以 POST 发送请求并解码 CommonResp：success=false 返回 *BusinessError。
+// 参数：form 非 nil 时以表单发送，否则 in 以 JSON 发送（in 为 nil 时不带请求体）。
+func Invoke[T any](ctx context.Context, t *Transport, c Call, u string, form url.Values, in any) (T, error) {
+	var resp CommonResp[T]
+	var err error
+	switch {
+	case form != nil:
+		err = t.PostForm(ctx, c, u, form, &resp)
+	case in != nil:
+		err = t.PostJSON(ctx, c, u, in, &resp)
+	default:
+		err = t.h.do(ctx, c, http.MethodPost, u, "", nil, &resp)
+	}
+	if err != nil {
+		return resp.Data, err
+	}
+	if !resp.Success {
+		return resp.Data, &BusinessError{Call: c, Message: strings.TrimSpace(resp.Message)}
+	}
+	return resp.Data, nil
+}
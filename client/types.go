@@ -56,6 +56,7 @@ type TaskTrackerReportInstanceStatusReq struct {
 	ReportTime     int64  `json:"reportTime"`
 	SourceAddress  string `json:"sourceAddress"`
 	InstanceStatus int    `json:"instanceStatus"`
+	Result         string `json:"result,omitempty"` // 终态结果消息
 }
 
 // WorkerLogReportReq 在线日志上报。
@@ -0,0 +1,287 @@
+// Package jobsync 将处理器声明的任务定义（processor.JobDefinition）同步到 PowerJob-Server：
+// 对比 Server 上的任务生成计划（新建/更新/不变），可仅输出计划（dry-run）或通过 OpenAPI 执行。
+package jobsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mengeric/powerjob-client-go/openapi"
+	"github.com/mengeric/powerjob-client-go/processor"
+)
+
+// JobAPI 同步所需的 OpenAPI 能力，*openapi.Client 即满足。
+type JobAPI interface {
+	FetchAllJobs(ctx context.Context) ([]openapi.JobInfo, error)
+	SaveJob(ctx context.Context, req openapi.SaveJobRequest) (int64, error)
+}
+
+// Action 计划中的动作。
+type Action string
+
+const (
+	ActionCreate    Action = "CREATE"    // Server 上不存在该处理器的任务
+	ActionUpdate    Action = "UPDATE"    // 任务存在但与定义不一致
+	ActionUnchanged Action = "UNCHANGED" // 任务与定义一致
+)
+
+// defaultConcurrency 新建任务时未声明 Concurrency 使用的值，与控制台默认一致。
+const defaultConcurrency = 5
+
+// FieldDiff 单个字段的差异。
+type FieldDiff struct {
+	Field string
+	From  any
+	To    any
+}
+
+// Change 单个处理器的同步动作；Request 为将要提交的保存请求。
+type Change struct {
+	Action  Action
+	Key     string
+	JobID   int64
+	Diffs   []FieldDiff
+	Request openapi.SaveJobRequest
+}
+
+// Plan 同步计划，按处理器 key 排序。
+type Plan struct {
+	Changes []Change
+}
+
+// Pending 返回需要提交的变更（新建与更新）。
+func (p Plan) Pending() []Change {
+	var out []Change
+	for _, c := range p.Changes {
+		if c.Action != ActionUnchanged {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// String 以可读形式输出计划，每行一个处理器：+ 新建，~ 更新（附字段差异），= 不变。
+func (p Plan) String() string {
+	var b strings.Builder
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			fmt.Fprintf(&b, "+ %s: create job %q (%s %s)\n", c.Key, c.Request.JobName, c.Request.TimeExpressionType, c.Request.TimeExpression)
+		case ActionUpdate:
+			fmt.Fprintf(&b, "~ %s: update job #%d\n", c.Key, c.JobID)
+			for _, d := range c.Diffs {
+				fmt.Fprintf(&b, "    %s: %v -> %v\n", d.Field, d.From, d.To)
+			}
+		default:
+			fmt.Fprintf(&b, "= %s: job #%d unchanged\n", c.Key, c.JobID)
+		}
+	}
+	return b.String()
+}
+
+// Build 对比定义与 Server 上的任务生成计划。
+// 匹配规则：processorType 为 BUILT_IN 且 processorInfo 等于处理器 key；存在多个时优先任务名一致者，否则取 ID 最小者。
+func Build(defs map[string]processor.JobDefinition, existing []openapi.JobInfo) (Plan, error) {
+	keys := make([]string, 0, len(defs))
+	for k := range defs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var plan Plan
+	var errs []error
+	for _, key := range keys {
+		def := defs[key]
+		if err := validate(def); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		cur, ok := match(key, name(key, def), existing)
+		if !ok {
+			req := desired(key, def, openapi.SaveJobRequest{
+				JobName: key, TimeExpressionType: openapi.TimeAPI, ExecuteType: openapi.ExecuteStandalone,
+				ProcessorType: openapi.ProcessorBuiltIn, Concurrency: defaultConcurrency, Enable: true,
+			})
+			plan.Changes = append(plan.Changes, Change{Action: ActionCreate, Key: key, Request: req})
+			continue
+		}
+		plan.Changes = append(plan.Changes, compare(key, def, cur))
+	}
+	return plan, errors.Join(errs...)
+}
+
+// compare 对比定义与已有任务，生成更新或不变的变更。
+func compare(key string, def processor.JobDefinition, cur openapi.JobInfo) Change {
+	base := cur.SaveRequest()
+	req := desired(key, def, base)
+	c := Change{Action: ActionUnchanged, Key: key, JobID: cur.ID, Request: req, Diffs: diff(base, req)}
+	if len(c.Diffs) > 0 {
+		c.Action = ActionUpdate
+	}
+	return c
+}
+
+// validate 校验定义本身的一致性。
+func validate(def processor.JobDefinition) error {
+	switch {
+	case def.Cron != "" && def.FixedRate > 0:
+		return errors.New("cron and fixed rate are mutually exclusive")
+	case def.FixedRate < 0 || def.Timeout < 0 || def.Retries < 0 || def.MaxInstances < 0 || def.Concurrency < 0:
+		return errors.New("durations and counts must not be negative")
+	}
+	return nil
+}
+
+func name(key string, def processor.JobDefinition) string {
+	if def.Name != "" {
+		return def.Name
+	}
+	return key
+}
+
+// match 查找处理器 key 对应的已有任务。
+func match(key, jobName string, existing []openapi.JobInfo) (openapi.JobInfo, bool) {
+	var found []openapi.JobInfo
+	for _, j := range existing {
+		if j.ProcessorType == 1 && j.ProcessorInfo == key {
+			found = append(found, j)
+		}
+	}
+	if len(found) == 0 {
+		return openapi.JobInfo{}, false
+	}
+	sort.Slice(found, func(i, k int) bool { return found[i].ID < found[k].ID })
+	for _, j := range found {
+		if j.JobName == jobName {
+			return j, true
+		}
+	}
+	return found[0], true
+}
+
+// desired 在 base 基础上覆盖定义中声明的字段：零值字段一律保留 base（新建时即 Server 默认值），
+// Cron 与 FixedRate 均为空时保留现有调度方式；Disabled 只会停用任务，不会重新启用已停用的任务。
+func desired(key string, def processor.JobDefinition, base openapi.SaveJobRequest) openapi.SaveJobRequest {
+	req := base
+	req.ProcessorInfo = key
+	if def.Name != "" {
+		req.JobName = def.Name
+	}
+	if def.Description != "" {
+		req.JobDescription = def.Description
+	}
+	if def.Params != "" {
+		req.JobParams = def.Params
+	}
+	switch {
+	case def.Cron != "":
+		req.TimeExpressionType, req.TimeExpression = openapi.TimeCron, def.Cron
+	case def.FixedRate > 0:
+		req.TimeExpressionType, req.TimeExpression = openapi.TimeFixedRate, fmt.Sprint(def.FixedRate.Milliseconds())
+	}
+	if def.Timeout > 0 {
+		req.InstanceTimeLimit = def.Timeout.Milliseconds()
+	}
+	if def.Retries > 0 {
+		req.InstanceRetryNum = def.Retries
+	}
+	if def.MaxInstances > 0 {
+		req.MaxInstanceNum = def.MaxInstances
+	}
+	if def.Concurrency > 0 {
+		req.Concurrency = def.Concurrency
+	}
+	if len(def.Tags) > 0 {
+		req.Tag = strings.Join(def.Tags, ",")
+	}
+	if def.Disabled {
+		req.Enable = false
+	}
+	return req
+}
+
+// diffFields 参与对比的字段（SaveJobRequest 的 Go 字段名与展示名）。
+var diffFields = []struct{ field, label string }{
+	{"JobName", "jobName"}, {"JobDescription", "jobDescription"}, {"JobParams", "jobParams"},
+	{"TimeExpressionType", "timeExpressionType"}, {"TimeExpression", "timeExpression"},
+	{"InstanceTimeLimit", "instanceTimeLimit"}, {"InstanceRetryNum", "instanceRetryNum"},
+	{"MaxInstanceNum", "maxInstanceNum"}, {"Concurrency", "concurrency"}, {"Tag", "tag"}, {"Enable", "enable"},
+}
+
+func diff(from, to openapi.SaveJobRequest) []FieldDiff {
+	fv, tv := reflect.ValueOf(from), reflect.ValueOf(to)
+	var out []FieldDiff
+	for _, f := range diffFields {
+		a, b := fv.FieldByName(f.field).Interface(), tv.FieldByName(f.field).Interface()
+		if a != b {
+			out = append(out, FieldDiff{Field: f.label, From: a, To: b})
+		}
+	}
+	return out
+}
+
+// Option Reconciler 的可选项。
+type Option func(*Reconciler)
+
+// DryRun 只生成计划，不提交任何变更。
+func DryRun() Option { return func(r *Reconciler) { r.dryRun = true } }
+
+// Reconciler 执行同步。
+type Reconciler struct {
+	api    JobAPI
+	dryRun bool
+}
+
+// New 构造同步器。
+func New(api JobAPI, opts ...Option) *Reconciler {
+	r := &Reconciler{api: api}
+	for _, fn := range opts {
+		fn(r)
+	}
+	return r
+}
+
+// DryRun 是否为仅输出计划模式。
+func (r *Reconciler) DryRun() bool { return r.dryRun }
+
+// Run 拉取 Server 上的任务、生成计划并（非 dry-run 时）逐个提交。
+// 返回：计划（新建成功的变更会回填 JobID）与错误；单个定义或提交失败不影响其它处理器，错误合并返回。
+// 注意：同步假定只有一个写入方。新建前会重新拉取任务列表，若其它副本已创建同一任务则改为对比更新，
+// 但 OpenAPI 没有原子的“不存在才创建”，多个副本同时启动仍可能各自新建，应只在一个副本或发布流水线中提交。
+func (r *Reconciler) Run(ctx context.Context, defs map[string]processor.JobDefinition) (Plan, error) {
+	existing, err := r.api.FetchAllJobs(ctx)
+	if err != nil {
+		return Plan{}, fmt.Errorf("fetch jobs: %w", err)
+	}
+	plan, err := Build(defs, existing)
+	if r.dryRun {
+		return plan, err
+	}
+	errs := []error{err}
+	for i, c := range plan.Changes {
+		if c.Action == ActionCreate {
+			fresh, err := r.api.FetchAllJobs(ctx)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: fetch jobs: %w", c.Key, err))
+				continue
+			}
+			if cur, ok := match(c.Key, c.Request.JobName, fresh); ok {
+				c = compare(c.Key, defs[c.Key], cur)
+				plan.Changes[i] = c
+			}
+		}
+		if c.Action == ActionUnchanged {
+			continue
+		}
+		id, err := r.api.SaveJob(ctx, c.Request)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: save job: %w", c.Key, err))
+			continue
+		}
+		plan.Changes[i].JobID = id
+	}
+	return plan, errors.Join(errs...)
+}
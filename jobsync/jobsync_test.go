@@ -0,0 +1,146 @@
+package jobsync
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/openapi"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeAPI 内存中的任务列表。
+type fakeAPI struct {
+	jobs    []openapi.JobInfo
+	saved   []openapi.SaveJobRequest
+	fetches int
+	racing  []openapi.JobInfo // 首次拉取之后出现的任务，模拟其它副本并发创建
+}
+
+func (f *fakeAPI) FetchAllJobs(ctx context.Context) ([]openapi.JobInfo, error) {
+	f.fetches++
+	if f.fetches > 1 {
+		f.jobs, f.racing = append(f.jobs, f.racing...), nil
+	}
+	return f.jobs, nil
+}
+
+func (f *fakeAPI) SaveJob(ctx context.Context, req openapi.SaveJobRequest) (int64, error) {
+	f.saved = append(f.saved, req)
+	if req.ID == 0 {
+		return 1000 + int64(len(f.saved)), nil
+	}
+	return req.ID, nil
+}
+
+func TestReconciler(t *testing.T) {
+	Convey("definitions should be diffed against server jobs", t, func() {
+		api := &fakeAPI{jobs: []openapi.JobInfo{
+			{ID: 10, JobName: "settle", ProcessorType: 1, ProcessorInfo: "order.settle", TimeExpressionType: 2, TimeExpression: "0 0 1 * * ?",
+				ExecuteType: 1, Concurrency: 5, Status: openapi.JobEnabled, MinCPUCores: 2},
+			{ID: 11, JobName: "report", ProcessorType: 1, ProcessorInfo: "report.daily", TimeExpressionType: 3, TimeExpression: "60000",
+				ExecuteType: 1, Concurrency: 5, InstanceRetryNum: 2, Status: openapi.JobEnabled},
+		}}
+		defs := map[string]processor.JobDefinition{
+			"order.settle": {Name: "settle", Cron: "0 0 2 * * ?", Timeout: time.Minute, Tags: []string{"billing", "daily"}},
+			"report.daily": {Name: "report", FixedRate: time.Minute, Retries: 2},
+			"user.cleanup": {Params: `{"days":30}`},
+		}
+
+		Convey("dry-run only reports the plan", func() {
+			plan, err := New(api, DryRun()).Run(context.Background(), defs)
+			So(err, ShouldBeNil)
+			So(api.saved, ShouldBeEmpty)
+			So(len(plan.Changes), ShouldEqual, 3)
+			So(plan.Changes[0].Action, ShouldEqual, ActionUpdate)
+			So(plan.Changes[0].JobID, ShouldEqual, 10)
+			So(plan.Changes[1].Action, ShouldEqual, ActionUnchanged)
+			So(plan.Changes[2].Action, ShouldEqual, ActionCreate)
+			So(len(plan.Pending()), ShouldEqual, 2)
+
+			out := plan.String()
+			So(out, ShouldContainSubstring, `+ user.cleanup: create job "user.cleanup" (API )`)
+			So(out, ShouldContainSubstring, "timeExpression: 0 0 1 * * ? -> 0 0 2 * * ?")
+			So(out, ShouldContainSubstring, "instanceTimeLimit: 0 -> 60000")
+			So(out, ShouldContainSubstring, "tag:  -> billing,daily")
+			So(out, ShouldContainSubstring, "= report.daily: job #11 unchanged")
+		})
+
+		Convey("apply creates and updates only what changed", func() {
+			plan, err := New(api).Run(context.Background(), defs)
+			So(err, ShouldBeNil)
+			So(len(api.saved), ShouldEqual, 2)
+			upd := api.saved[0]
+			So(upd.ID, ShouldEqual, 10)
+			So(upd.MinCPUCores, ShouldEqual, 2) // 未声明的字段保留 Server 现值
+			So(upd.ProcessorType, ShouldEqual, openapi.ProcessorBuiltIn)
+			So(upd.Tag, ShouldEqual, "billing,daily")
+			created := api.saved[1]
+			So(created.ProcessorInfo, ShouldEqual, "user.cleanup")
+			So(created.Concurrency, ShouldEqual, defaultConcurrency)
+			So(created.Enable, ShouldBeTrue)
+			So(plan.Changes[2].JobID, ShouldEqual, 1002)
+		})
+
+		Convey("jobs created by another replica meanwhile are not created twice", func() {
+			api.racing = []openapi.JobInfo{{ID: 12, JobName: "user.cleanup", ProcessorType: 1, ProcessorInfo: "user.cleanup",
+				TimeExpressionType: 1, ExecuteType: 1, Concurrency: 5, Status: openapi.JobEnabled}}
+			plan, err := New(api).Run(context.Background(), defs)
+			So(err, ShouldBeNil)
+			So(len(api.saved), ShouldEqual, 2)
+			So(api.saved[1].ID, ShouldEqual, 12)
+			So(api.saved[1].JobParams, ShouldEqual, `{"days":30}`)
+			So(plan.Changes[2].Action, ShouldEqual, ActionUpdate)
+			So(plan.Changes[2].JobID, ShouldEqual, 12)
+		})
+
+		Convey("updates keep fields the definition does not mention", func() {
+			api.jobs[0].JobDescription, api.jobs[0].JobParams = "console", `{"batch":100}`
+			api.jobs[0].InstanceRetryNum, api.jobs[0].MaxInstanceNum = 3, 1
+			api.jobs[0].Status = openapi.JobDisabled
+			api.jobs[0].NotifyUserIDs = json.RawMessage(`"7,8"`)
+			api.jobs[0].DispatchStrategy = json.RawMessage(`2`)
+			api.jobs[0].LifeCycle = json.RawMessage(`"{\"start\":1700000000000}"`)
+			api.jobs[0].AlarmConfig = json.RawMessage(`{"alertThreshold":3}`)
+			api.jobs[0].LogConfig = json.RawMessage(`{"type":2,"level":3}`)
+			defs["order.settle"] = processor.JobDefinition{Name: "settle", Cron: "0 0 3 * * ?"}
+
+			plan, err := Build(defs, api.jobs)
+			So(err, ShouldBeNil)
+			c := plan.Changes[0]
+			So(c.Action, ShouldEqual, ActionUpdate)
+			So(len(c.Diffs), ShouldEqual, 1)
+			So(c.Diffs[0].Field, ShouldEqual, "timeExpression")
+			req := c.Request
+			So(req.JobDescription, ShouldEqual, "console")
+			So(req.JobParams, ShouldEqual, `{"batch":100}`)
+			So(req.InstanceRetryNum, ShouldEqual, 3)
+			So(req.MaxInstanceNum, ShouldEqual, 1)
+			So(req.Enable, ShouldBeFalse) // 控制台停用的任务不会被重新启用
+			So(req.NotifyUserIDs, ShouldResemble, []int64{7, 8})
+			So(req.DispatchStrategy, ShouldEqual, openapi.DispatchRandom)
+			So(string(req.LifeCycle), ShouldEqual, `{"start":1700000000000}`)
+			So(string(req.AlarmConfig), ShouldEqual, `{"alertThreshold":3}`)
+			So(string(req.LogConfig), ShouldEqual, `{"type":2,"level":3}`)
+
+			Convey("and Disabled only ever disables", func() {
+				api.jobs[0].Status = openapi.JobEnabled
+				defs["order.settle"] = processor.JobDefinition{Name: "settle", Cron: "0 0 3 * * ?", Disabled: true}
+				plan, err := Build(defs, api.jobs)
+				So(err, ShouldBeNil)
+				So(plan.Changes[0].Request.Enable, ShouldBeFalse)
+			})
+		})
+
+		Convey("invalid definitions are reported without blocking others", func() {
+			defs["bad"] = processor.JobDefinition{Cron: "* * * * * ?", FixedRate: time.Second}
+			_, err := New(api).Run(context.Background(), defs)
+			So(err, ShouldNotBeNil)
+			So(strings.Contains(err.Error(), "bad: cron and fixed rate"), ShouldBeTrue)
+			So(len(api.saved), ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook 用于拦截日志并执行附加行为（例如在线日志上报）。
+// 注意：Hook 内部不应再次调用 logging.L() 以避免递归。
+type Hook func(ctx context.Context, level int, msg string, args ...any)
+
+// HookHandle 为 AddHook 返回的注册句柄，用于移除对应 Hook。
+// 零值句柄调用 Remove 为空操作。
+type HookHandle struct{ id uint64 }
+
+// Remove 移除该句柄对应的 Hook；重复调用安全。
+func (h HookHandle) Remove() { RemoveHook(h) }
+
+// hookEntry 注册表中的单个 Hook。
+type hookEntry struct {
+	id uint64
+	fn Hook
+}
+
+var (
+	hookMu     sync.Mutex
+	hookSeq    uint64
+	hooks      atomic.Value // stores []hookEntry（写时复制，读路径无锁）
+	legacyHook HookHandle   // SetHook 占用的槽位
+)
+
+// AddHook 注册一个日志 Hook，多个 Hook 按注册顺序依次调用。
+// 参数：h 为 Hook 函数，nil 将被忽略。
+// 返回：注册句柄，调用 Remove/RemoveHook 可移除。
+// 说明：单个 Hook panic 会被捕获并输出到 stderr，不影响其它 Hook 与日志本身。
+func AddHook(h Hook) HookHandle {
+	if h == nil {
+		return HookHandle{}
+	}
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	return addLocked(h)
+}
+
+// RemoveHook 按句柄移除 Hook；句柄不存在时为空操作。
+func RemoveHook(h HookHandle) {
+	if h.id == 0 {
+		return
+	}
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	removeLocked(h.id)
+}
+
+// SetHook 设置全局日志 Hook；传入 nil 表示移除 Hook。
+// 兼容旧版本：仅替换通过 SetHook 设置的那一个 Hook，不影响 AddHook 注册的其它 Hook。
+func SetHook(h Hook) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	removeLocked(legacyHook.id)
+	legacyHook = HookHandle{}
+	if h != nil {
+		legacyHook = addLocked(h)
+	}
+}
+
+// addLocked 追加一个 Hook 并返回句柄，调用方需持有 hookMu。
+func addLocked(h Hook) HookHandle {
+	hookSeq++
+	cur := loadHooks()
+	next := make([]hookEntry, 0, len(cur)+1)
+	next = append(next, cur...)
+	next = append(next, hookEntry{id: hookSeq, fn: h})
+	hooks.Store(next)
+	return HookHandle{id: hookSeq}
+}
+
+// removeLocked 从注册表中删除指定 id，调用方需持有 hookMu。
+func removeLocked(id uint64) {
+	if id == 0 {
+		return
+	}
+	cur := loadHooks()
+	next := make([]hookEntry, 0, len(cur))
+	for _, e := range cur {
+		if e.id != id {
+			next = append(next, e)
+		}
+	}
+	hooks.Store(next)
+}
+
+func loadHooks() []hookEntry {
+	if v := hooks.Load(); v != nil {
+		return v.([]hookEntry)
+	}
+	return nil
+}
+
+// callHook 依次调用所有已注册 Hook。
+func callHook(ctx context.Context, level int, msg string, args ...any) {
+	for _, e := range loadHooks() {
+		safeCall(e.fn, ctx, level, msg, args...)
+	}
+}
+
+// safeCall 调用单个 Hook 并吞掉其 panic；此处不能使用 L()，否则可能再次触发 Hook。
+func safeCall(h Hook, ctx context.Context, level int, msg string, args ...any) {
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "logging: hook panic recovered: %v\n%s", r, debug.Stack())
+		}
+	}()
+	h(ctx, level, msg, args...)
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHooks(t *testing.T) {
+	Convey("multiple hooks should all be called and removable", t, func() {
+		var a, b int32
+		ha := AddHook(func(ctx context.Context, level int, msg string, args ...any) { atomic.AddInt32(&a, 1) })
+		hb := AddHook(func(ctx context.Context, level int, msg string, args ...any) { atomic.AddInt32(&b, 1) })
+		defer hb.Remove()
+
+		L().Infof(context.Background(), "one")
+		So(atomic.LoadInt32(&a), ShouldEqual, 1)
+		So(atomic.LoadInt32(&b), ShouldEqual, 1)
+
+		ha.Remove()
+		ha.Remove() // 重复移除安全
+		L().Infof(context.Background(), "two")
+		So(atomic.LoadInt32(&a), ShouldEqual, 1)
+		So(atomic.LoadInt32(&b), ShouldEqual, 2)
+	})
+
+	Convey("a panicking hook should not break other hooks", t, func() {
+		var called int32
+		hp := AddHook(func(ctx context.Context, level int, msg string, args ...any) { panic("boom") })
+		defer hp.Remove()
+		hc := AddHook(func(ctx context.Context, level int, msg string, args ...any) { atomic.AddInt32(&called, 1) })
+		defer hc.Remove()
+
+		So(func() { L().Warnf(context.Background(), "x") }, ShouldNotPanic)
+		So(atomic.LoadInt32(&called), ShouldEqual, 1)
+	})
+
+	Convey("SetHook should only replace its own slot", t, func() {
+		var legacy1, legacy2, added int32
+		h := AddHook(func(ctx context.Context, level int, msg string, args ...any) { atomic.AddInt32(&added, 1) })
+		defer h.Remove()
+		SetHook(func(ctx context.Context, level int, msg string, args ...any) { atomic.AddInt32(&legacy1, 1) })
+		SetHook(func(ctx context.Context, level int, msg string, args ...any) { atomic.AddInt32(&legacy2, 1) })
+		L().Errorf(context.Background(), "x")
+		So(atomic.LoadInt32(&legacy1), ShouldEqual, 0)
+		So(atomic.LoadInt32(&legacy2), ShouldEqual, 1)
+		So(atomic.LoadInt32(&added), ShouldEqual, 1)
+
+		SetHook(nil)
+		L().Errorf(context.Background(), "y")
+		So(atomic.LoadInt32(&legacy2), ShouldEqual, 1)
+		So(atomic.LoadInt32(&added), ShouldEqual, 2)
+	})
+	Convey("concurrent SetHook should leave exactly one legacy hook", t, func() {
+		base := len(loadHooks())
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				SetHook(func(ctx context.Context, level int, msg string, args ...any) {})
+			}()
+		}
+		wg.Wait()
+		So(len(loadHooks()), ShouldEqual, base+1)
+		SetHook(nil)
+		So(len(loadHooks()), ShouldEqual, base)
+	})
+}
@@ -5,7 +5,6 @@ import (
     "fmt"
     "log/slog"
     "os"
-    "sync/atomic"
 )
 
 // Logger 日志门面接口（fmt 风格）。
@@ -80,21 +79,3 @@ func SetGlobal(l Logger) {
         defaultLogger = l
     }
 }
-
-// Hook 用于拦截日志并执行附加行为（例如在线日志上报）。
-// 注意：Hook 内部不应再次调用 logging.L() 以避免递归。
-type Hook func(ctx context.Context, level int, msg string, args ...any)
-
-var hook atomic.Value // stores Hook
-
-// SetHook 设置全局日志 Hook；传入 nil 表示移除 Hook。
-func SetHook(h Hook) {
-    if h == nil { hook.Store(nil); return }
-    hook.Store(h)
-}
-
-func callHook(ctx context.Context, level int, msg string, args ...any) {
-    if v := hook.Load(); v != nil {
-        v.(Hook)(ctx, level, msg, args...)
-    }
-}
@@ -0,0 +1,49 @@
+package metrics
+
+import "sync"
+
+// OtherLabel 标签数达到 CounterVec.MaxLabels 后，新标签的计数归入该标签。
+const OtherLabel = "other"
+
+// CounterVec 按标签分组的并发安全计数器，零值可直接使用。
+// 标签来自外部输入（如请求中的 processorInfo）时应设置 MaxLabels，避免标签数无限增长。
+type CounterVec struct {
+	MaxLabels int // 最多保留的不同标签数（含 OtherLabel），<=0 表示不限制
+
+	mu sync.Mutex
+	m  map[string]int64
+}
+
+// Inc 对 label 计数加一。
+func (c *CounterVec) Inc(label string) { c.Add(label, 1) }
+
+// Add 对 label 计数增加 n。
+func (c *CounterVec) Add(label string, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.m == nil {
+		c.m = map[string]int64{}
+	}
+	if _, ok := c.m[label]; !ok && c.MaxLabels > 0 && len(c.m) >= c.MaxLabels-1 {
+		label = OtherLabel
+	}
+	c.m[label] += n
+}
+
+// Get 读取 label 当前计数。
+func (c *CounterVec) Get(label string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m[label]
+}
+
+// Snapshot 返回全部计数的副本。
+func (c *CounterVec) Snapshot() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]int64, len(c.m))
+	for k, v := range c.m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCounterVec(t *testing.T) {
+	Convey("CounterVec should count per label concurrently", t, func() {
+		var c CounterVec
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Inc("a")
+				c.Add("b", 2)
+			}()
+		}
+		wg.Wait()
+		So(c.Get("a"), ShouldEqual, 10)
+		So(c.Get("missing"), ShouldEqual, 0)
+		snap := c.Snapshot()
+		So(snap, ShouldResemble, map[string]int64{"a": 10, "b": 20})
+		snap["a"] = 0
+		So(c.Get("a"), ShouldEqual, 10)
+	})
+	Convey("MaxLabels should fold new labels into other", t, func() {
+		c := CounterVec{MaxLabels: 3}
+		for _, l := range []string{"a", "b", "c", "d", "a"} {
+			c.Inc(l)
+		}
+		So(c.Snapshot(), ShouldResemble, map[string]int64{"a": 2, "b": 1, OtherLabel: 2})
+	})
+}
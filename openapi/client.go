@@ -0,0 +1,154 @@
+// Package openapi 封装 PowerJob-Server 的 OpenAPI（/openApi/*），用于以编程方式管理任务、实例与工作流。
+// 传输复用 client.Transport，因此超时、重试、熔断、TLS 与埋点 Hook 与 Worker 侧一致。
+//
+// 实例日志：PowerJob 的 OpenAPI 没有日志查询接口（在线日志只能通过控制台需登录的接口查看），
+// 因此本包有意不提供日志拉取；实例的结果消息可通过 FetchInstanceInfo 返回的 InstanceInfo.Result 获取。
+package openapi
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/mengeric/powerjob-client-go/client"
+)
+
+// 访问令牌相关请求头（PowerJob 5.x 起的 authApp 鉴权）。
+const (
+	HeaderAccessToken = "X-POWERJOB-ACCESS-TOKEN"
+	HeaderAppID       = "X-POWERJOB-APP-ID"
+)
+
+// Client PowerJob OpenAPI 客户端，并发安全。
+// 首次调用需要 appId 的接口时自动完成应用校验（assert 或 authApp），之后复用结果。
+type Client struct {
+	t        *client.Transport
+	addr     string
+	appName  string
+	password string
+	useToken bool
+
+	mu    sync.Mutex
+	appID int64
+	token string
+}
+
+// Option New 的可选项。
+type Option func(*Client)
+
+// WithClientOptions 定制底层 HTTP 传输（http.Client、TLS、超时、重试、熔断、Hook 等）。
+func WithClientOptions(opts ...client.ClientOption) Option {
+	return func(c *Client) { c.t = client.NewTransport(append(opts, client.WithRequestHook(c.authHeaders))...) }
+}
+
+// WithTokenAuth 使用 authApp 换取访问令牌并在后续请求头中携带（PowerJob 5.x 开启 OpenAPI 鉴权时需要）；
+// 默认使用 assert 校验应用名与密码。
+func WithTokenAuth() Option { return func(c *Client) { c.useToken = true } }
+
+// New 构造客户端。
+// 参数：addr 为 Server 地址（host:port 或带 scheme 的 URL），appName/password 为控制台中注册的应用名与密码。
+func New(addr, appName, password string, opts ...Option) *Client {
+	c := &Client{addr: addr, appName: appName, password: password}
+	c.t = client.NewTransport(client.WithRequestHook(c.authHeaders))
+	for _, fn := range opts {
+		fn(c)
+	}
+	c.t.Idempotent(idempotentCalls...)
+	return c
+}
+
+// AppID 返回应用ID，未校验过时先完成校验。
+func (c *Client) AppID(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	id := c.appID
+	c.mu.Unlock()
+	if id > 0 {
+		return id, nil
+	}
+	if c.useToken {
+		res, err := c.Auth(ctx)
+		return res.AppID, err
+	}
+	return c.Assert(ctx)
+}
+
+// Assert 校验应用名与密码（/openApi/assert），返回应用ID。
+func (c *Client) Assert(ctx context.Context) (int64, error) {
+	id, err := invoke[int64](ctx, c, "assert", url.Values{"appName": {c.appName}, "password": {c.password}}, nil)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	c.appID = id
+	c.mu.Unlock()
+	return id, nil
+}
+
+// Auth 以应用名与 MD5 后的密码换取访问令牌（/openApi/authApp），后续请求自动携带。
+func (c *Client) Auth(ctx context.Context) (AppAuthResult, error) {
+	sum := md5.Sum([]byte(c.password))
+	req := AppAuthRequest{AppName: c.appName, EncryptedPassword: hex.EncodeToString(sum[:]), EncryptType: "md5"}
+	res, err := invoke[AppAuthResult](ctx, c, "authApp", nil, req)
+	if err != nil {
+		return res, err
+	}
+	c.mu.Lock()
+	c.appID, c.token = res.AppID, res.Token
+	c.mu.Unlock()
+	return res, nil
+}
+
+// authHeaders 为请求附加访问令牌与应用ID。
+func (c *Client) authHeaders(ctx context.Context, _ client.Call, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" {
+		return
+	}
+	r.Header.Set(HeaderAccessToken, c.token)
+	r.Header.Set(HeaderAppID, strconv.FormatInt(c.appID, 10))
+}
+
+// appForm 构造带 appId 的表单参数，kv 为成对的键值。
+func (c *Client) appForm(ctx context.Context, kv ...string) (url.Values, error) {
+	id, err := c.AppID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v := url.Values{"appId": {strconv.FormatInt(id, 10)}}
+	for i := 0; i+1 < len(kv); i += 2 {
+		v.Set(kv[i], kv[i+1])
+	}
+	return v, nil
+}
+
+// idempotentCalls 只读接口，失败时可按重试策略重试。
+var idempotentCalls = []client.Call{
+	callOf("assert"), callOf("fetchJob"), callOf("fetchAllJob"), callOf("fetchInstanceStatus"),
+	callOf("fetchInstanceInfo"), callOf("fetchWorkflow"), callOf("fetchWfInstanceInfo"),
+}
+
+// callOf 返回 OpenAPI 接口对应的调用类型，用于超时配置与埋点，如 "openapi.saveJob"。
+func callOf(name string) client.Call { return client.Call("openapi." + name) }
+
+// invoke 调用 /openApi/<name> 并解码 CommonResp。
+func invoke[T any](ctx context.Context, c *Client, name string, form url.Values, in any) (T, error) {
+	return client.Invoke[T](ctx, c.t, callOf(name), c.t.URL(c.addr, "/openApi/"+name), form, in)
+}
+
+// invokeApp 调用需要 appId 的表单接口，kv 为除 appId 外的参数。
+func invokeApp[T any](ctx context.Context, c *Client, name string, kv ...string) (T, error) {
+	form, err := c.appForm(ctx, kv...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return invoke[T](ctx, c, name, form, nil)
+}
+
+// id 格式化 ID 参数。
+func id(v int64) string { return strconv.FormatInt(v, 10) }
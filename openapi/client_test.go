@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeServer 记录 OpenAPI 请求并按路径返回预设结果。
+type fakeServer struct {
+	mu      sync.Mutex
+	forms   map[string]map[string]string
+	bodies  map[string]json.RawMessage
+	headers map[string]http.Header
+	data    map[string]any
+	fail    map[string]string
+}
+
+func newFakeServer() (*fakeServer, *httptest.Server) {
+	f := &fakeServer{forms: map[string]map[string]string{}, bodies: map[string]json.RawMessage{},
+		headers: map[string]http.Header{}, data: map[string]any{}, fail: map[string]string{}}
+	return f, httptest.NewServer(f)
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := r.URL.Path[len("/openApi/"):]
+	f.headers[name] = r.Header.Clone()
+	if r.Header.Get("Content-Type") == "application/json" {
+		var raw json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&raw)
+		f.bodies[name] = raw
+	} else {
+		_ = r.ParseForm()
+		form := map[string]string{}
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		f.forms[name] = form
+	}
+	if msg, ok := f.fail[name]; ok {
+		_ = json.NewEncoder(w).Encode(client.CommonResp[any]{Success: false, Message: msg})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(client.CommonResp[any]{Success: true, Data: f.data[name]})
+}
+
+func TestClient_Jobs(t *testing.T) {
+	Convey("job and instance operations should map to the OpenAPI", t, func() {
+		f, ts := newFakeServer()
+		defer ts.Close()
+		f.data["assert"] = 7
+		f.data["saveJob"] = 100
+		f.data["runJob"] = 9001
+		f.data["fetchInstanceInfo"] = InstanceInfo{InstanceID: 9001, JobID: 100, Status: InstanceSucceed, Result: "ok"}
+		f.data["fetchAllJob"] = []JobInfo{{ID: 100, JobName: "settle", ProcessorInfo: "order.settle", Status: JobEnabled}}
+		ctx := context.Background()
+		c := New(ts.Listener.Addr().String(), "demo", "pwd")
+
+		jobID, err := c.SaveJob(ctx, SaveJobRequest{JobName: "settle", TimeExpressionType: TimeCron, TimeExpression: "0 0 * * * ?",
+			ExecuteType: ExecuteStandalone, ProcessorType: ProcessorBuiltIn, ProcessorInfo: "order.settle", Enable: true})
+		So(err, ShouldBeNil)
+		So(jobID, ShouldEqual, 100)
+		So(f.forms["assert"], ShouldResemble, map[string]string{"appName": "demo", "password": "pwd"})
+		var saved SaveJobRequest
+		_ = json.Unmarshal(f.bodies["saveJob"], &saved)
+		So(saved.AppID, ShouldEqual, 7)
+		So(saved.TimeExpressionType, ShouldEqual, TimeCron)
+
+		iid, err := c.RunJob(ctx, jobID, `{"date":"2024-01-01"}`, 2*time.Second)
+		So(err, ShouldBeNil)
+		So(iid, ShouldEqual, 9001)
+		So(f.forms["runJob"], ShouldResemble, map[string]string{"appId": "7", "jobId": "100", "instanceParams": `{"date":"2024-01-01"}`, "delay": "2000"})
+
+		info, err := c.FetchInstanceInfo(ctx, iid)
+		So(err, ShouldBeNil)
+		So(info.Status, ShouldEqual, InstanceSucceed)
+		So(info.Result, ShouldEqual, "ok")
+
+		jobs, err := c.FetchAllJobs(ctx)
+		So(err, ShouldBeNil)
+		So(len(jobs), ShouldEqual, 1)
+
+		So(c.StopInstance(ctx, iid), ShouldBeNil)
+		So(f.forms["stopInstance"]["instanceId"], ShouldEqual, "9001")
+		So(c.DisableJob(ctx, jobID), ShouldBeNil)
+		So(c.DeleteJob(ctx, jobID), ShouldBeNil)
+
+		Convey("success=false surfaces as BusinessError with the server message", func() {
+			f.fail["retryInstance"] = "instance is running"
+			err := c.RetryInstance(ctx, iid)
+			var be *client.BusinessError
+			So(errors.As(err, &be), ShouldBeTrue)
+			So(be.Message, ShouldEqual, "instance is running")
+			So(be.Call, ShouldEqual, client.Call("openapi.retryInstance"))
+		})
+
+		Convey("a failed assert is returned to the caller", func() {
+			f.fail["assert"] = "password error"
+			_, err := New(ts.Listener.Addr().String(), "demo", "bad").FetchJob(ctx, 1)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestClient_Workflows(t *testing.T) {
+	Convey("workflow operations should map to the OpenAPI", t, func() {
+		f, ts := newFakeServer()
+		defer ts.Close()
+		f.data["assert"] = 7
+		f.data["saveWorkflow"] = 50
+		f.data["runWorkflow"] = 500
+		ctx := context.Background()
+		c := New(ts.Listener.Addr().String(), "demo", "pwd")
+
+		wfID, err := c.SaveWorkflow(ctx, SaveWorkflowRequest{WfName: "daily", TimeExpressionType: TimeAPI,
+			DAG: &WorkflowDAG{Nodes: []WorkflowNode{{JobID: 1, Enable: true}}}})
+		So(err, ShouldBeNil)
+		So(wfID, ShouldEqual, 50)
+		wfi, err := c.RunWorkflow(ctx, wfID, "init", 0)
+		So(err, ShouldBeNil)
+		So(wfi, ShouldEqual, 500)
+		So(f.forms["runWorkflow"]["initParams"], ShouldEqual, "init")
+		So(c.StopWorkflowInstance(ctx, wfi), ShouldBeNil)
+		So(f.forms["stopWfInstance"]["wfInstanceId"], ShouldEqual, "500")
+	})
+}
+
+func TestClient_TokenAuth(t *testing.T) {
+	Convey("token auth should send the access token on later requests", t, func() {
+		f, ts := newFakeServer()
+		defer ts.Close()
+		f.data["authApp"] = AppAuthResult{AppID: 8, Token: "tok"}
+		f.data["fetchJob"] = JobInfo{ID: 3}
+		c := New(ts.Listener.Addr().String(), "demo", "pwd", WithTokenAuth(), WithClientOptions(client.WithTimeout(time.Second)))
+
+		job, err := c.FetchJob(context.Background(), 3)
+		So(err, ShouldBeNil)
+		So(job.ID, ShouldEqual, 3)
+		var req AppAuthRequest
+		_ = json.Unmarshal(f.bodies["authApp"], &req)
+		sum := md5.Sum([]byte("pwd"))
+		So(req.EncryptedPassword, ShouldEqual, hex.EncodeToString(sum[:]))
+		So(req.EncryptType, ShouldEqual, "md5")
+		So(f.headers["fetchJob"].Get(HeaderAccessToken), ShouldEqual, "tok")
+		So(f.headers["fetchJob"].Get(HeaderAppID), ShouldEqual, "8")
+		So(f.forms["fetchJob"]["appId"], ShouldEqual, "8")
+	})
+}
@@ -0,0 +1,33 @@
+package openapi
+
+import "context"
+
+// 说明：PowerJob OpenAPI 不提供实例日志接口，本文件只包含实例控制与状态/详情查询，见包文档。
+
+// StopInstance 停止运行中的实例。
+func (c *Client) StopInstance(ctx context.Context, instanceID int64) error {
+	_, err := invokeApp[any](ctx, c, "stopInstance", "instanceId", id(instanceID))
+	return err
+}
+
+// CancelInstance 取消尚未开始执行的实例（如延迟触发的实例）。
+func (c *Client) CancelInstance(ctx context.Context, instanceID int64) error {
+	_, err := invokeApp[any](ctx, c, "cancelInstance", "instanceId", id(instanceID))
+	return err
+}
+
+// RetryInstance 重试已结束的实例。
+func (c *Client) RetryInstance(ctx context.Context, instanceID int64) error {
+	_, err := invokeApp[any](ctx, c, "retryInstance", "instanceId", id(instanceID))
+	return err
+}
+
+// FetchInstanceStatus 查询实例状态（取值见 Instance* 常量）。
+func (c *Client) FetchInstanceStatus(ctx context.Context, instanceID int64) (int, error) {
+	return invokeApp[int](ctx, c, "fetchInstanceStatus", "instanceId", id(instanceID))
+}
+
+// FetchInstanceInfo 查询实例详情。
+func (c *Client) FetchInstanceInfo(ctx context.Context, instanceID int64) (InstanceInfo, error) {
+	return invokeApp[InstanceInfo](ctx, c, "fetchInstanceInfo", "instanceId", id(instanceID))
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// SaveJob 创建或更新任务，返回任务ID。
+func (c *Client) SaveJob(ctx context.Context, req SaveJobRequest) (int64, error) {
+	if req.AppID == 0 {
+		appID, err := c.AppID(ctx)
+		if err != nil {
+			return 0, err
+		}
+		req.AppID = appID
+	}
+	return invoke[int64](ctx, c, "saveJob", nil, req)
+}
+
+// FetchJob 查询任务详情。
+func (c *Client) FetchJob(ctx context.Context, jobID int64) (JobInfo, error) {
+	return invokeApp[JobInfo](ctx, c, "fetchJob", "jobId", id(jobID))
+}
+
+// FetchAllJobs 查询应用下的全部任务。
+func (c *Client) FetchAllJobs(ctx context.Context) ([]JobInfo, error) {
+	return invokeApp[[]JobInfo](ctx, c, "fetchAllJob")
+}
+
+// EnableJob 启用任务。
+func (c *Client) EnableJob(ctx context.Context, jobID int64) error {
+	_, err := invokeApp[any](ctx, c, "enableJob", "jobId", id(jobID))
+	return err
+}
+
+// DisableJob 停用任务。
+func (c *Client) DisableJob(ctx context.Context, jobID int64) error {
+	_, err := invokeApp[any](ctx, c, "disableJob", "jobId", id(jobID))
+	return err
+}
+
+// DeleteJob 删除任务。
+func (c *Client) DeleteJob(ctx context.Context, jobID int64) error {
+	_, err := invokeApp[any](ctx, c, "deleteJob", "jobId", id(jobID))
+	return err
+}
+
+// RunJob 立即（或延迟 delay 后）触发任务，instanceParams 作为本次实例参数，返回实例ID。
+func (c *Client) RunJob(ctx context.Context, jobID int64, instanceParams string, delay time.Duration) (int64, error) {
+	return invokeApp[int64](ctx, c, "runJob", "jobId", id(jobID), "instanceParams", instanceParams,
+		"delay", strconv.FormatInt(delay.Milliseconds(), 10))
+}
@@ -0,0 +1,317 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// TimeExpressionType 时间表达式类型（与 PowerJob 枚举名一致）。
+type TimeExpressionType string
+
+const (
+	TimeAPI        TimeExpressionType = "API"         // 仅通过 API 触发
+	TimeCron       TimeExpressionType = "CRON"        // CRON 表达式
+	TimeFixedRate  TimeExpressionType = "FIXED_RATE"  // 固定频率（毫秒）
+	TimeFixedDelay TimeExpressionType = "FIXED_DELAY" // 固定延迟（毫秒）
+	TimeWorkflow   TimeExpressionType = "WORKFLOW"    // 由工作流触发
+)
+
+// ExecuteType 执行类型。
+type ExecuteType string
+
+const (
+	ExecuteStandalone ExecuteType = "STANDALONE"
+	ExecuteBroadcast  ExecuteType = "BROADCAST"
+	ExecuteMapReduce  ExecuteType = "MAP_REDUCE"
+	ExecuteMap        ExecuteType = "MAP"
+)
+
+// ProcessorType 处理器类型；Go Worker 仅支持 BUILT_IN。
+type ProcessorType string
+
+const (
+	ProcessorBuiltIn  ProcessorType = "BUILT_IN"
+	ProcessorExternal ProcessorType = "EXTERNAL"
+)
+
+// DispatchStrategy 派发策略。
+type DispatchStrategy string
+
+const (
+	DispatchHealthFirst DispatchStrategy = "HEALTH_FIRST"
+	DispatchRandom      DispatchStrategy = "RANDOM"
+)
+
+// 任务状态（JobInfo.Status）。
+const (
+	JobEnabled  = 1
+	JobDisabled = 2
+)
+
+// 实例状态（InstanceInfo.Status）。
+const (
+	InstanceWaitingDispatch      = 1
+	InstanceWaitingWorkerReceive = 2
+	InstanceRunning              = 3
+	InstanceFailed               = 4
+	InstanceSucceed              = 5
+	InstanceCanceled             = 9
+	InstanceStopped              = 10
+)
+
+// AppAuthRequest authApp 请求体。
+type AppAuthRequest struct {
+	AppName           string `json:"appName"`
+	EncryptedPassword string `json:"encryptedPassword"`
+	EncryptType       string `json:"encryptType"`
+}
+
+// AppAuthResult authApp 结果。
+type AppAuthResult struct {
+	AppID int64  `json:"appId"`
+	Token string `json:"token"`
+}
+
+// SaveJobRequest 创建或更新任务；ID 为 0 时创建，AppID 为 0 时自动填充。
+// 时间相关字段单位为毫秒，InstanceTimeLimit 为 0 表示不限制。
+type SaveJobRequest struct {
+	ID                 int64              `json:"id,omitempty"`
+	JobName            string             `json:"jobName"`
+	JobDescription     string             `json:"jobDescription,omitempty"`
+	AppID              int64              `json:"appId"`
+	JobParams          string             `json:"jobParams,omitempty"`
+	TimeExpressionType TimeExpressionType `json:"timeExpressionType"`
+	TimeExpression     string             `json:"timeExpression,omitempty"`
+	ExecuteType        ExecuteType        `json:"executeType"`
+	ProcessorType      ProcessorType      `json:"processorType"`
+	ProcessorInfo      string             `json:"processorInfo"`
+	MaxInstanceNum     int                `json:"maxInstanceNum"`
+	Concurrency        int                `json:"concurrency"`
+	InstanceTimeLimit  int64              `json:"instanceTimeLimit"`
+	InstanceRetryNum   int                `json:"instanceRetryNum"`
+	TaskRetryNum       int                `json:"taskRetryNum"`
+	MinCPUCores        float64            `json:"minCpuCores"`
+	MinMemorySpace     float64            `json:"minMemorySpace"`
+	MinDiskSpace       float64            `json:"minDiskSpace"`
+	Enable             bool               `json:"enable"`
+	DesignatedWorkers  string             `json:"designatedWorkers,omitempty"`
+	MaxWorkerCount     int                `json:"maxWorkerCount"`
+	NotifyUserIDs      []int64            `json:"notifyUserIds,omitempty"`
+	Extra              string             `json:"extra,omitempty"`
+	DispatchStrategy   DispatchStrategy   `json:"dispatchStrategy,omitempty"`
+	Tag                string             `json:"tag,omitempty"`
+	// 以下为控制台维护的高级配置，保留 Server 原始 JSON 对象（通常来自 JobInfo.SaveRequest）。
+	LifeCycle             json.RawMessage `json:"lifeCycle,omitempty"`
+	AlarmConfig           json.RawMessage `json:"alarmConfig,omitempty"`
+	LogConfig             json.RawMessage `json:"logConfig,omitempty"`
+	AdvancedRuntimeConfig json.RawMessage `json:"advancedRuntimeConfig,omitempty"`
+}
+
+// JobInfo 任务详情（fetchJob/fetchAllJob）。枚举字段为 PowerJob 的数值编码：
+// TimeExpressionType 1=API 2=CRON 3=FIXED_RATE 4=FIXED_DELAY 5=WORKFLOW；ExecuteType 1=STANDALONE 2=BROADCAST 3=MAP_REDUCE 4=MAP；
+// ProcessorType 1=BUILT_IN 4=EXTERNAL。
+type JobInfo struct {
+	ID                 int64   `json:"id"`
+	JobName            string  `json:"jobName"`
+	JobDescription     string  `json:"jobDescription"`
+	AppID              int64   `json:"appId"`
+	JobParams          string  `json:"jobParams"`
+	TimeExpressionType int     `json:"timeExpressionType"`
+	TimeExpression     string  `json:"timeExpression"`
+	ExecuteType        int     `json:"executeType"`
+	ProcessorType      int     `json:"processorType"`
+	ProcessorInfo      string  `json:"processorInfo"`
+	MaxInstanceNum     int     `json:"maxInstanceNum"`
+	Concurrency        int     `json:"concurrency"`
+	InstanceTimeLimit  int64   `json:"instanceTimeLimit"`
+	InstanceRetryNum   int     `json:"instanceRetryNum"`
+	TaskRetryNum       int     `json:"taskRetryNum"`
+	MinCPUCores        float64 `json:"minCpuCores"`
+	MinMemorySpace     float64 `json:"minMemorySpace"`
+	MinDiskSpace       float64 `json:"minDiskSpace"`
+	Status             int     `json:"status"`
+	DesignatedWorkers  string  `json:"designatedWorkers"`
+	MaxWorkerCount     int     `json:"maxWorkerCount"`
+	Extra              string  `json:"extra"`
+	Tag                string  `json:"tag"`
+	// 以下字段的编码随 Server 版本不同（逗号分隔字符串/数组、数值/枚举名、JSON 字符串/对象），
+	// 保留原始 JSON，由 SaveRequest 转换为保存请求的格式，避免更新任务时丢失控制台配置。
+	NotifyUserIDs         json.RawMessage `json:"notifyUserIds,omitempty"`
+	DispatchStrategy      json.RawMessage `json:"dispatchStrategy,omitempty"`
+	LifeCycle             json.RawMessage `json:"lifecycle,omitempty"`
+	AlarmConfig           json.RawMessage `json:"alarmConfig,omitempty"`
+	LogConfig             json.RawMessage `json:"logConfig,omitempty"`
+	AdvancedRuntimeConfig json.RawMessage `json:"advancedRuntimeConfig,omitempty"`
+}
+
+// InstanceInfo 任务实例详情，时间字段为毫秒时间戳。
+type InstanceInfo struct {
+	InstanceID          int64  `json:"instanceId"`
+	JobID               int64  `json:"jobId"`
+	AppID               int64  `json:"appId"`
+	WfInstanceID        int64  `json:"wfInstanceId"`
+	JobParams           string `json:"jobParams"`
+	InstanceParams      string `json:"instanceParams"`
+	Status              int    `json:"status"`
+	Result              string `json:"result"`
+	ExpectedTriggerTime int64  `json:"expectedTriggerTime"`
+	ActualTriggerTime   int64  `json:"actualTriggerTime"`
+	FinishedTime        int64  `json:"finishedTime"`
+	LastReportTime      int64  `json:"lastReportTime"`
+	TaskTrackerAddress  string `json:"taskTrackerAddress"`
+	RunningTimes        int64  `json:"runningTimes"`
+}
+
+// WorkflowNode 工作流节点，NodeID 在保存后由 Server 分配。
+type WorkflowNode struct {
+	NodeID         int64  `json:"nodeId,omitempty"`
+	JobID          int64  `json:"jobId"`
+	NodeName       string `json:"nodeName,omitempty"`
+	NodeParams     string `json:"nodeParams,omitempty"`
+	Enable         bool   `json:"enable"`
+	SkipWhenFailed bool   `json:"skipWhenFailed"`
+	Status         int    `json:"status,omitempty"`
+	InstanceID     int64  `json:"instanceId,omitempty"`
+	Result         string `json:"result,omitempty"`
+}
+
+// WorkflowEdge 工作流有向边。
+type WorkflowEdge struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// WorkflowDAG 工作流 DAG。
+type WorkflowDAG struct {
+	Nodes []WorkflowNode `json:"nodes"`
+	Edges []WorkflowEdge `json:"edges"`
+}
+
+// SaveWorkflowRequest 创建或更新工作流；ID 为 0 时创建，AppID 为 0 时自动填充。
+type SaveWorkflowRequest struct {
+	ID                 int64              `json:"id,omitempty"`
+	WfName             string             `json:"wfName"`
+	WfDescription      string             `json:"wfDescription,omitempty"`
+	AppID              int64              `json:"appId"`
+	TimeExpressionType TimeExpressionType `json:"timeExpressionType"`
+	TimeExpression     string             `json:"timeExpression,omitempty"`
+	MaxWfInstanceNum   int                `json:"maxWfInstanceNum"`
+	Enable             bool               `json:"enable"`
+	NotifyUserIDs      []int64            `json:"notifyUserIds,omitempty"`
+	DAG                *WorkflowDAG       `json:"dag,omitempty"`
+}
+
+// WorkflowInfo 工作流详情。
+type WorkflowInfo struct {
+	ID                 int64        `json:"id"`
+	WfName             string       `json:"wfName"`
+	WfDescription      string       `json:"wfDescription"`
+	AppID              int64        `json:"appId"`
+	TimeExpressionType int          `json:"timeExpressionType"`
+	TimeExpression     string       `json:"timeExpression"`
+	MaxWfInstanceNum   int          `json:"maxWfInstanceNum"`
+	Status             int          `json:"status"`
+	DAG                *WorkflowDAG `json:"pEWorkflowDAG,omitempty"`
+}
+
+// WorkflowInstanceInfo 工作流实例详情；DAG 保留 Server 原始 JSON。
+type WorkflowInstanceInfo struct {
+	WfInstanceID      int64           `json:"wfInstanceId"`
+	WorkflowID        int64           `json:"workflowId"`
+	AppID             int64           `json:"appId"`
+	Status            int             `json:"status"`
+	WfInitParams      string          `json:"wfInitParams"`
+	Result            string          `json:"result"`
+	DAG               json.RawMessage `json:"dag,omitempty"`
+	ActualTriggerTime int64           `json:"actualTriggerTime"`
+	FinishedTime      int64           `json:"finishedTime"`
+}
+
+// 数值编码与枚举名的对应关系（JobInfo 使用数值，SaveJobRequest 使用枚举名）。
+var (
+	timeTypeNames = map[int]TimeExpressionType{1: TimeAPI, 2: TimeCron, 3: TimeFixedRate, 4: TimeFixedDelay, 5: TimeWorkflow}
+	executeNames  = map[int]ExecuteType{1: ExecuteStandalone, 2: ExecuteBroadcast, 3: ExecuteMapReduce, 4: ExecuteMap}
+	procTypeNames = map[int]ProcessorType{1: ProcessorBuiltIn, 4: ProcessorExternal}
+)
+
+// SaveRequest 将任务详情转换为保存请求，用于在现有配置基础上修改部分字段后调用 SaveJob。
+func (j JobInfo) SaveRequest() SaveJobRequest {
+	return SaveJobRequest{
+		ID:                    j.ID,
+		JobName:               j.JobName,
+		JobDescription:        j.JobDescription,
+		AppID:                 j.AppID,
+		JobParams:             j.JobParams,
+		TimeExpressionType:    timeTypeNames[j.TimeExpressionType],
+		TimeExpression:        j.TimeExpression,
+		ExecuteType:           executeNames[j.ExecuteType],
+		ProcessorType:         procTypeNames[j.ProcessorType],
+		ProcessorInfo:         j.ProcessorInfo,
+		MaxInstanceNum:        j.MaxInstanceNum,
+		Concurrency:           j.Concurrency,
+		InstanceTimeLimit:     j.InstanceTimeLimit,
+		InstanceRetryNum:      j.InstanceRetryNum,
+		TaskRetryNum:          j.TaskRetryNum,
+		MinCPUCores:           j.MinCPUCores,
+		MinMemorySpace:        j.MinMemorySpace,
+		MinDiskSpace:          j.MinDiskSpace,
+		Enable:                j.Status == JobEnabled,
+		DesignatedWorkers:     j.DesignatedWorkers,
+		MaxWorkerCount:        j.MaxWorkerCount,
+		Extra:                 j.Extra,
+		Tag:                   j.Tag,
+		NotifyUserIDs:         userIDs(j.NotifyUserIDs),
+		DispatchStrategy:      dispatchName(j.DispatchStrategy),
+		LifeCycle:             jsonObject(j.LifeCycle),
+		AlarmConfig:           jsonObject(j.AlarmConfig),
+		LogConfig:             jsonObject(j.LogConfig),
+		AdvancedRuntimeConfig: jsonObject(j.AdvancedRuntimeConfig),
+	}
+}
+
+// dispatchNames 派发策略的数值编码。
+var dispatchNames = map[int]DispatchStrategy{1: DispatchHealthFirst, 2: DispatchRandom}
+
+// userIDs 解析报警用户ID：数组或逗号分隔的字符串，无法识别的项被忽略。
+func userIDs(raw json.RawMessage) []int64 {
+	var ids []int64
+	if json.Unmarshal(raw, &ids) == nil {
+		return ids
+	}
+	var s string
+	if json.Unmarshal(raw, &s) != nil {
+		return nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// dispatchName 解析派发策略：枚举名或数值编码。
+func dispatchName(raw json.RawMessage) DispatchStrategy {
+	var n int
+	if json.Unmarshal(raw, &n) == nil {
+		return dispatchNames[n]
+	}
+	var s string
+	_ = json.Unmarshal(raw, &s)
+	return DispatchStrategy(s)
+}
+
+// jsonObject 返回配置对象的 JSON：Server 以 JSON 字符串返回时先解码，空值返回 nil。
+func jsonObject(raw json.RawMessage) json.RawMessage {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		raw = json.RawMessage(s)
+	}
+	switch v := strings.TrimSpace(string(raw)); v {
+	case "", "null", "{}":
+		return nil
+	default:
+		return json.RawMessage(v)
+	}
+}
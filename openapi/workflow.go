@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// SaveWorkflow 创建或更新工作流，返回工作流ID。
+func (c *Client) SaveWorkflow(ctx context.Context, req SaveWorkflowRequest) (int64, error) {
+	if req.AppID == 0 {
+		appID, err := c.AppID(ctx)
+		if err != nil {
+			return 0, err
+		}
+		req.AppID = appID
+	}
+	return invoke[int64](ctx, c, "saveWorkflow", nil, req)
+}
+
+// FetchWorkflow 查询工作流详情。
+func (c *Client) FetchWorkflow(ctx context.Context, workflowID int64) (WorkflowInfo, error) {
+	return invokeApp[WorkflowInfo](ctx, c, "fetchWorkflow", "workflowId", id(workflowID))
+}
+
+// EnableWorkflow 启用工作流。
+func (c *Client) EnableWorkflow(ctx context.Context, workflowID int64) error {
+	_, err := invokeApp[any](ctx, c, "enableWorkflow", "workflowId", id(workflowID))
+	return err
+}
+
+// DisableWorkflow 停用工作流。
+func (c *Client) DisableWorkflow(ctx context.Context, workflowID int64) error {
+	_, err := invokeApp[any](ctx, c, "disableWorkflow", "workflowId", id(workflowID))
+	return err
+}
+
+// DeleteWorkflow 删除工作流。
+func (c *Client) DeleteWorkflow(ctx context.Context, workflowID int64) error {
+	_, err := invokeApp[any](ctx, c, "deleteWorkflow", "workflowId", id(workflowID))
+	return err
+}
+
+// RunWorkflow 触发工作流，initParams 为初始参数，返回工作流实例ID。
+func (c *Client) RunWorkflow(ctx context.Context, workflowID int64, initParams string, delay time.Duration) (int64, error) {
+	return invokeApp[int64](ctx, c, "runWorkflow", "workflowId", id(workflowID), "initParams", initParams,
+		"delay", strconv.FormatInt(delay.Milliseconds(), 10))
+}
+
+// StopWorkflowInstance 停止工作流实例。
+func (c *Client) StopWorkflowInstance(ctx context.Context, wfInstanceID int64) error {
+	_, err := invokeApp[any](ctx, c, "stopWfInstance", "wfInstanceId", id(wfInstanceID))
+	return err
+}
+
+// RetryWorkflowInstance 重试失败的工作流实例。
+func (c *Client) RetryWorkflowInstance(ctx context.Context, wfInstanceID int64) error {
+	_, err := invokeApp[any](ctx, c, "retryWfInstance", "wfInstanceId", id(wfInstanceID))
+	return err
+}
+
+// FetchWorkflowInstanceInfo 查询工作流实例详情。
+func (c *Client) FetchWorkflowInstanceInfo(ctx context.Context, wfInstanceID int64) (WorkflowInstanceInfo, error) {
+	return invokeApp[WorkflowInstanceInfo](ctx, c, "fetchWfInstanceInfo", "wfInstanceId", id(wfInstanceID))
+}
@@ -0,0 +1,102 @@
+package powerjob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/auth"
+	"github.com/mengeric/powerjob-client-go/logging"
+)
+
+// maxAuthBody 鉴权时读取请求体的上限，超出时拒绝请求。
+const maxAuthBody = 8 << 20
+
+// guard 为端点加上鉴权：未配置鉴权器时直接放行；拒绝时写审计日志并返回 401/403。
+func (w *Worker) guard(h http.HandlerFunc) http.HandlerFunc {
+	if w.authn == nil {
+		return h
+	}
+	return func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, maxAuthBody))
+		if err != nil {
+			w.audit(r, err)
+			writeErr(rw, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err := w.authn.Authenticate(r, body); err != nil {
+			w.audit(r, err)
+			code := http.StatusUnauthorized
+			if errors.Is(err, auth.ErrForbidden) && !errors.Is(err, auth.ErrUnauthenticated) {
+				code = http.StatusForbidden
+			}
+			writeErr(rw, code, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h(rw, r)
+	}
+}
+
+// audit 记录被拒绝的请求；未配置 WithAuditLog 时输出 WARN 日志。
+func (w *Worker) audit(r *http.Request, err error) {
+	ev := auth.AuditEvent{Time: time.Now(), RemoteAddr: r.RemoteAddr, Method: r.Method, Path: r.URL.Path, Reason: err.Error()}
+	if w.auditFn != nil {
+		w.auditFn(ev)
+		return
+	}
+	logging.L().Warnf(context.Background(), "auth rejected: remote=%s method=%s path=%s reason=%s", ev.RemoteAddr, ev.Method, ev.Path, ev.Reason)
+}
+
+// serverAddrTTL 服务发现得到过的 Server 地址在不再是当前地址后继续放行的时长。
+const serverAddrTTL = 10 * time.Minute
+
+// serverSet 记录 Bootstrap 与服务发现得到过的 Server 地址及最近一次出现的时间。
+type serverSet struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// ServerAddresses 返回 Bootstrap 地址、服务发现的当前地址，以及 10 分钟内曾是当前地址的 Server 地址，
+// 可作为 auth.IPAllowList 的动态来源；Server 切换后旧地址过期即不再放行。
+func (w *Worker) ServerAddresses() []string {
+	return w.servers.addresses(time.Now(), w.opt.BootstrapServer, w.discovered())
+}
+
+// addresses 刷新 current 中地址的出现时间，淘汰超过 serverAddrTTL 未出现的地址并返回其余地址。
+func (s *serverSet) addresses(now time.Time, current ...string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = map[string]time.Time{}
+	}
+	for _, a := range current {
+		if a != "" {
+			s.seen[a] = now
+		}
+	}
+	out := make([]string, 0, len(s.seen))
+	for a, at := range s.seen {
+		if now.Sub(at) > serverAddrTTL {
+			delete(s.seen, a)
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// discovered 返回服务发现的当前 Server 地址，Start 之前为空。
+func (w *Worker) discovered() string {
+	w.discMu.RLock()
+	defer w.discMu.RUnlock()
+	if w.disc == nil {
+		return ""
+	}
+	return w.disc.Get()
+}
@@ -0,0 +1,96 @@
+package powerjob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/auth"
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWorker_Auth(t *testing.T) {
+	Convey("endpoints should require authentication and audit rejections", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("auth.ok", func(ctx context.Context, in string) (string, error) { return "ok", nil }))
+		secret := []byte("k")
+		var mu sync.Mutex
+		var audits []auth.AuditEvent
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("auth"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI{}),
+			WithAuthenticator(auth.HMAC(time.Minute, secret)),
+			WithAuditLog(func(ev auth.AuditEvent) { mu.Lock(); audits = append(audits, ev); mu.Unlock() }))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		body, _ := json.Marshal(client.ServerScheduleJobReq{InstanceID: 131, ProcessorInfo: "auth.ok"})
+		send := func(sign bool) *http.Response {
+			req, _ := http.NewRequest(http.MethodPost, "http://"+w.Addr()+"/worker/runJob", bytes.NewReader(body))
+			if sign {
+				auth.Sign(req, secret, body)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return nil
+			}
+			_ = resp.Body.Close()
+			return resp
+		}
+
+		resp := send(false)
+		So(resp.StatusCode, ShouldEqual, http.StatusUnauthorized)
+		mu.Lock()
+		So(len(audits), ShouldEqual, 1)
+		So(audits[0].Path, ShouldEqual, "/worker/runJob")
+		So(audits[0].Reason, ShouldContainSubstring, "missing signature")
+		mu.Unlock()
+		_, err := w.store.Get(ctx, 131)
+		So(err, ShouldEqual, ErrNotFound)
+
+		resp = send(true)
+		So(resp.StatusCode, ShouldEqual, http.StatusOK)
+		time.Sleep(20 * time.Millisecond)
+		rec, err := w.store.Get(ctx, 131)
+		So(err, ShouldBeNil)
+		So(rec.Status, ShouldEqual, StateSucceed)
+	})
+
+	Convey("server allowlist should admit bootstrap and discovered server addresses only", t, func() {
+		deny, _ := auth.AllowList()
+		wd := NewWorker(WithBootstrapServer("192.0.2.10:7700"), WithAppName("allow"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI{}),
+			WithServerAllowList(deny), WithAuditLog(func(auth.AuditEvent) {}))
+		allow, _ := auth.AllowList()
+		wa := NewWorker(WithBootstrapServer("127.0.0.1:7700"), WithAppName("allow"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI{}),
+			WithServerAllowList(allow))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go wd.Start(ctx)
+		go wa.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		_, code := postAsk[any](wd.Addr(), "/worker/queryInstanceStatus", map[string]any{"instanceId": 1})
+		So(code, ShouldEqual, http.StatusForbidden)
+		_, code = postAsk[any](wa.Addr(), "/worker/queryInstanceStatus", map[string]any{"instanceId": 1})
+		So(code, ShouldEqual, http.StatusNotFound)
+		So(wa.ServerAddresses(), ShouldContain, "127.0.0.1:7700")
+	})
+}
+
+func TestServerSet_Expiry(t *testing.T) {
+	Convey("addresses no longer current expire after the TTL", t, func() {
+		var s serverSet
+		now := time.Now()
+		So(s.addresses(now, "boot", "a"), ShouldHaveLength, 2)
+		So(s.addresses(now.Add(time.Minute), "boot", "b"), ShouldHaveLength, 3)
+		got := s.addresses(now.Add(serverAddrTTL+2*time.Second), "boot", "b")
+		So(got, ShouldHaveLength, 2)
+		So(got, ShouldNotContain, "a")
+	})
+}
@@ -0,0 +1,35 @@
+package powerjob
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxDecodedBody 解压后请求体的上限，防止压缩炸弹。
+const maxDecodedBody = 8 << 20
+
+// decompress 支持 Content-Encoding: gzip 的请求体：解压后交给 h，不支持的编码返回 415。
+// 说明：位于 guard 之内，鉴权签名针对实际传输的（压缩后）请求体。
+func (w *Worker) decompress(h http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		switch enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); enc {
+		case "", "identity":
+			h(rw, r)
+		case "gzip":
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				writeErr(rw, http.StatusBadRequest, fmt.Errorf("invalid gzip body: %w", err))
+				return
+			}
+			defer zr.Close()
+			r.Body = http.MaxBytesReader(rw, zr, maxDecodedBody)
+			r.Header.Del("Content-Encoding")
+			r.ContentLength = -1
+			h(rw, r)
+		default:
+			writeErr(rw, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content encoding %q", enc))
+		}
+	}
+}
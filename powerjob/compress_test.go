@@ -0,0 +1,66 @@
+package powerjob
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/auth"
+	"github.com/mengeric/powerjob-client-go/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// postEncoded 以指定 Content-Encoding 发送请求体，sign 非空时用该密钥签名实际发送的字节。
+func postEncoded(addr, path, enc string, body []byte, sign []byte) (askResp[RunJobResult], int) {
+	var out askResp[RunJobResult]
+	req, _ := http.NewRequest(http.MethodPost, "http://"+addr+path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", enc)
+	if sign != nil {
+		auth.Sign(req, sign, body)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return out, 0
+	}
+	defer resp.Body.Close()
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return out, resp.StatusCode
+}
+
+func gzipJSON(v any) []byte {
+	raw, _ := json.Marshal(v)
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write(raw)
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestWorker_GzipRequests(t *testing.T) {
+	Convey("worker endpoints should accept gzip request bodies", t, func() {
+		secret := []byte("s3cret")
+		w := NewWorker(WithBootstrapServer("x"), WithAppName("gz"), WithListenAddr("127.0.0.1:0"),
+			WithClientAPI(&dummyAPI{}), WithAuthenticator(auth.HMAC(time.Minute, secret)))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		body := gzipJSON(client.ServerScheduleJobReq{InstanceID: 501, JobID: 5, ProcessorInfo: "simple"})
+		res, code := postEncoded(w.Addr(), "/worker/runJob", "gzip", body, secret)
+		So(code, ShouldEqual, http.StatusOK)
+		So(res.Success, ShouldBeTrue)
+		So(res.Data.Dispatch, ShouldEqual, DispatchAccepted)
+
+		_, code = postEncoded(w.Addr(), "/worker/runJob", "gzip", []byte("not gzip"), secret)
+		So(code, ShouldEqual, http.StatusBadRequest)
+
+		_, code = postEncoded(w.Addr(), "/worker/runJob", "br", []byte("{}"), secret)
+		So(code, ShouldEqual, http.StatusUnsupportedMediaType)
+	})
+}
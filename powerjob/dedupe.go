@@ -0,0 +1,57 @@
+package powerjob
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Dispatch 派发请求的受理结果。
+type Dispatch string
+
+const (
+	DispatchAccepted  Dispatch = "ACCEPTED"  // 新实例，已开始执行
+	DispatchDuplicate Dispatch = "DUPLICATE" // 同一实例正在运行，本次派发被忽略
+	DispatchCompleted Dispatch = "COMPLETED" // 同一实例已在去重窗口内结束，不再重复执行
+)
+
+// RunJobResult runJob 的受理结果；非 ACCEPTED 时 Status/ResultMsg 为已有记录的状态。
+type RunJobResult struct {
+	Dispatch   Dispatch `json:"dispatch"`
+	InstanceID int64    `json:"instanceId"`
+	Status     int      `json:"status"`
+	ResultMsg  string   `json:"resultMsg,omitempty"`
+}
+
+// dedupe 按实例ID对照 Storage 判断是否为重复派发，调用方需持有该实例的锁。
+// 规则：
+// - tracker 中仍在执行或记录为运行中：DUPLICATE；
+// - 记录为终态且结束时间（UpdatedAt）在 DedupeWindow 内：COMPLETED；
+// - 其它（无记录、记录已超出窗口、窗口为负数关闭终态去重）：ACCEPTED。
+// 存储实现 NotFoundReporter 时，Get 返回 ErrNotFound 以外的错误会被透传，由调用方以 503 拒绝。
+func (w *Worker) dedupe(ctx context.Context, instanceID int64) (RunJobResult, error) {
+	res := RunJobResult{Dispatch: DispatchAccepted, InstanceID: instanceID}
+	if _, ok := w.trk.Get(instanceID); ok {
+		res.Dispatch, res.Status = DispatchDuplicate, StateRunning
+		return res, nil
+	}
+	rec, err := w.store.Get(ctx, instanceID)
+	if err != nil {
+		if nf, ok := w.store.(NotFoundReporter); ok && nf.ReportsNotFound() && !errors.Is(err, ErrNotFound) {
+			// 无法确认是否执行过时拒绝受理，由 Server 稍后重试，避免重复执行
+			return res, err
+		}
+		// 记录不存在；未实现 NotFoundReporter 的存储无法区分故障与不存在，按不存在处理
+		return res, nil
+	}
+	switch {
+	case rec.Status == StateRunning:
+		res.Dispatch = DispatchDuplicate
+	case IsTerminal(rec.Status) && w.opt.DedupeWindow > 0 && time.Since(rec.UpdatedAt) <= w.opt.DedupeWindow:
+		res.Dispatch = DispatchCompleted
+	default:
+		return res, nil
+	}
+	res.Status, res.ResultMsg = rec.Status, rec.ResultMsg
+	return res, nil
+}
@@ -0,0 +1,144 @@
+package powerjob
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// postRunJob 派发实例并解析受理结果。
+func postRunJob(addr string, req client.ServerScheduleJobReq) (RunJobResult, int) {
+	out, code := postAsk[RunJobResult](addr, "/worker/runJob", req)
+	return out.Data, code
+}
+
+func TestWorker_DispatchDedupe(t *testing.T) {
+	Convey("repeated dispatches of one instance should run the processor once", t, func() {
+		var runs atomic.Int32
+		release := make(chan struct{})
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("dedupe.pay", func(ctx context.Context, in string) (string, error) {
+			runs.Add(1)
+			<-release
+			return "paid", nil
+		}))
+		st := NewMemoryStore()
+		old := time.Now().Add(-48 * time.Hour)
+		_ = st.Upsert(context.Background(), &InstanceRecord{InstanceID: 92, JobID: 9, Status: StateFailed, ResultMsg: "worker restarted", UpdatedAt: time.Now()})
+		_ = st.Upsert(context.Background(), &InstanceRecord{InstanceID: 93, JobID: 9, Status: StateSucceed, Reported: true, UpdatedAt: old})
+		w := NewWorker(WithRegistry(reg), WithStorage(st), WithBootstrapServer("x"), WithAppName("dd"), WithListenAddr("127.0.0.1:0"),
+			WithClientAPI(&dummyAPI{}), WithDedupeWindow(time.Hour))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		req := client.ServerScheduleJobReq{InstanceID: 91, JobID: 9, ProcessorInfo: "dedupe.pay"}
+		res, code := postRunJob(w.Addr(), req)
+		So(code, ShouldEqual, http.StatusOK)
+		So(res.Dispatch, ShouldEqual, DispatchAccepted)
+
+		res, _ = postRunJob(w.Addr(), req)
+		So(res.Dispatch, ShouldEqual, DispatchDuplicate)
+		So(res.Status, ShouldEqual, StateRunning)
+
+		close(release)
+		time.Sleep(30 * time.Millisecond)
+		res, _ = postRunJob(w.Addr(), req)
+		So(res.Dispatch, ShouldEqual, DispatchCompleted)
+		So(res.Status, ShouldEqual, StateSucceed)
+		So(res.ResultMsg, ShouldEqual, "paid")
+		So(runs.Load(), ShouldEqual, 1)
+
+		Convey("instances finished before a restart are not run again", func() {
+			res, _ := postRunJob(w.Addr(), client.ServerScheduleJobReq{InstanceID: 92, JobID: 9, ProcessorInfo: "dedupe.pay"})
+			So(res.Dispatch, ShouldEqual, DispatchCompleted)
+			So(res.Status, ShouldEqual, StateFailed)
+		})
+
+		Convey("records outside the window are dispatched again", func() {
+			res, _ := postRunJob(w.Addr(), client.ServerScheduleJobReq{InstanceID: 93, JobID: 9, ProcessorInfo: "dedupe.pay"})
+			So(res.Dispatch, ShouldEqual, DispatchAccepted)
+			time.Sleep(20 * time.Millisecond)
+			So(runs.Load(), ShouldEqual, 2)
+		})
+	})
+}
+
+// legacyStore 模拟按旧约定实现的存储：记录不存在时返回自定义错误，且未实现 NotFoundReporter。
+type legacyStore struct{ *memStore }
+
+func (s legacyStore) Get(ctx context.Context, id int64) (*InstanceRecord, error) {
+	if rec, err := s.memStore.Get(ctx, id); err == nil {
+		return rec, nil
+	}
+	return nil, errors.New("record missing")
+}
+
+// brokenStore 声明遵循 ErrNotFound 约定，但 Get 总是失败。
+type brokenStore struct{ *MemoryStore }
+
+func (s brokenStore) Get(ctx context.Context, id int64) (*InstanceRecord, error) {
+	return nil, errors.New("disk on fire")
+}
+
+func TestWorker_DispatchDedupeStorageErrors(t *testing.T) {
+	Convey("Get errors only block dispatch for stores that opt into ErrNotFound", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("dedupe.any", func(ctx context.Context, in string) (string, error) { return "", nil }))
+		start := func(st Storage) *Worker {
+			w := NewWorker(WithRegistry(reg), WithStorage(st), WithBootstrapServer("x"), WithAppName("dd"), WithListenAddr("127.0.0.1:0"),
+				WithClientAPI(&dummyAPI{}))
+			ctx, cancel := context.WithCancel(context.Background())
+			Reset(cancel)
+			go w.Start(ctx)
+			time.Sleep(50 * time.Millisecond)
+			return w
+		}
+		req := client.ServerScheduleJobReq{InstanceID: 95, JobID: 9, ProcessorInfo: "dedupe.any"}
+
+		res, code := postRunJob(start(legacyStore{&memStore{m: map[int64]*InstanceRecord{}}}).Addr(), req)
+		So(code, ShouldEqual, http.StatusOK)
+		So(res.Dispatch, ShouldEqual, DispatchAccepted)
+
+		_, code = postRunJob(start(brokenStore{NewMemoryStore()}).Addr(), req)
+		So(code, ShouldEqual, http.StatusServiceUnavailable)
+	})
+}
+
+// upsertFailStore Upsert 总是失败的存储。
+type upsertFailStore struct{ *MemoryStore }
+
+func (s upsertFailStore) Upsert(ctx context.Context, rec *InstanceRecord) error {
+	return errors.New("disk full")
+}
+
+func TestWorker_DispatchUpsertFailure(t *testing.T) {
+	Convey("a dispatch whose RUNNING record cannot be saved is rejected and not executed", t, func() {
+		var runs atomic.Int32
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("dedupe.save", func(ctx context.Context, in string) (string, error) { runs.Add(1); return "", nil }))
+		w := NewWorker(WithRegistry(reg), WithStorage(upsertFailStore{NewMemoryStore()}), WithBootstrapServer("x"), WithAppName("dd"),
+			WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI{}), WithMaxConcurrentInstances(1))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		for i := 0; i < 2; i++ { // 名额已归还，第二次同样得到 503 而不是 OVERLOADED
+			_, code := postRunJob(w.Addr(), client.ServerScheduleJobReq{InstanceID: 96, JobID: 9, ProcessorInfo: "dedupe.save"})
+			So(code, ShouldEqual, http.StatusServiceUnavailable)
+		}
+		time.Sleep(20 * time.Millisecond)
+		So(runs.Load(), ShouldEqual, 0)
+		_, ok := w.trk.Get(96)
+		So(ok, ShouldBeFalse)
+	})
+}
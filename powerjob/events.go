@@ -0,0 +1,284 @@
+package powerjob
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType 实例生命周期事件类型。
+type EventType string
+
+const (
+	EventStarted   EventType = "STARTED"   // 实例已接收并开始执行
+	EventProgress  EventType = "PROGRESS"  // 处理器通过 processor.SetProgress 更新了进度
+	EventSucceeded EventType = "SUCCEEDED" // 执行成功
+	EventFailed    EventType = "FAILED"    // 执行失败（含处理器未找到、参数错误等）
+	EventStopped   EventType = "STOPPED"   // 被 Server 停止
+	EventReported  EventType = "REPORTED"  // 终态已被 Server 确认（Reported=true）
+)
+
+// InstanceEvent 实例生命周期事件，Record 为状态变更后的完整记录快照。
+type InstanceEvent struct {
+	Type    EventType      `json:"type"`
+	Record  InstanceRecord `json:"record"`
+	At      time.Time      `json:"at"`
+	Dropped uint64         `json:"dropped,omitempty"` // 本订阅者在此事件之前累计丢弃的事件数，非 0 表示存在缺口
+}
+
+// EventFilter 订阅过滤条件；零值字段表示不按该维度过滤。
+type EventFilter struct {
+	Types      []EventType
+	JobID      int64
+	InstanceID int64
+}
+
+// Match 判断事件是否满足过滤条件。
+func (f EventFilter) Match(ev *InstanceEvent) bool {
+	if f.JobID != 0 && ev.Record.JobID != f.JobID {
+		return false
+	}
+	if f.InstanceID != 0 && ev.Record.InstanceID != f.InstanceID {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == ev.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// Backpressure 订阅者消费过慢（缓冲区已满）时的处理策略。
+type Backpressure int
+
+const (
+	DropNewest Backpressure = iota // 丢弃新事件（默认），不影响任务执行
+	DropOldest                     // 丢弃缓冲区中最旧的事件，保留最新状态
+	Block                          // 不丢事件：投递等待消费者取走事件。投递在后台进行，不阻塞派发、停止与状态上报，
+	// 但会推迟同一实例的后续事件；消费者长期不读时待投递事件在内存中累积，应及时取消订阅
+)
+
+// DefaultEventBuffer 订阅缓冲区默认容量。
+const DefaultEventBuffer = 256
+
+// SubscribeOption 订阅可选项。
+type SubscribeOption func(*subscriber)
+
+// WithEventBuffer 设置订阅缓冲区容量（<=0 时使用 DefaultEventBuffer）。
+func WithEventBuffer(n int) SubscribeOption {
+	return func(s *subscriber) {
+		if n > 0 {
+			s.ch = make(chan InstanceEvent, n)
+		}
+	}
+}
+
+// WithBackpressure 设置缓冲区已满时的处理策略。
+func WithBackpressure(p Backpressure) SubscribeOption { return func(s *subscriber) { s.policy = p } }
+
+// Subscribe 订阅本 Worker 的实例生命周期事件。
+// 功能：每次状态变更（开始、进度、成功、失败、停止、终态确认）都会投递一条带完整记录快照的事件；
+// 同一实例的事件按发生顺序投递，丢弃策略只会造成缺口而不会乱序。
+// 参数：ctx 控制订阅生命周期，ctx.Done 后返回的 channel 被关闭；f 过滤条件；opts 缓冲与背压策略。
+func (w *Worker) Subscribe(ctx context.Context, f EventFilter, opts ...SubscribeOption) <-chan InstanceEvent {
+	s := &subscriber{filter: f, done: make(chan struct{})}
+	for _, fn := range opts {
+		fn(s)
+	}
+	if s.ch == nil {
+		s.ch = make(chan InstanceEvent, DefaultEventBuffer)
+	}
+	w.bus.add(s)
+	go func() {
+		<-ctx.Done()
+		s.close() // 先关闭再注销：唤醒 Block 策略下阻塞中的投递
+		w.bus.remove(s)
+	}()
+	return s.ch
+}
+
+// eventBus 事件分发：实例锁内写存储并领取投递顺序，释放锁后再 publish；
+// 同一实例的事件按领取顺序依次投递，慢订阅者只会拖住该实例自身的后续事件。
+type eventBus struct {
+	mu    sync.RWMutex
+	subs  map[*subscriber]struct{}
+	count atomic.Int32
+	locks [32]sync.Mutex // 按实例ID分片，串行化同一实例的“写存储 + 领取投递顺序”
+
+	seqMu sync.Mutex
+	tails map[int64]chan struct{} // 每个实例最后一个待投递事件的完成信号
+}
+
+func (b *eventBus) add(s *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = map[*subscriber]struct{}{}
+	}
+	b.subs[s] = struct{}{}
+	b.count.Store(int32(len(b.subs)))
+}
+
+func (b *eventBus) remove(s *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, s)
+	b.count.Store(int32(len(b.subs)))
+}
+
+// active 是否存在订阅者；无订阅时跳过快照读取。
+func (b *eventBus) active() bool { return b.count.Load() > 0 }
+
+func (b *eventBus) lock(instanceID int64) *sync.Mutex {
+	return &b.locks[uint64(instanceID)%uint64(len(b.locks))]
+}
+
+// ticket 为实例领取一个投递顺序：prev 为前一个事件的完成信号（nil 表示无需等待），mine 投递完成后关闭。
+// 调用方需持有该实例的锁，以保证领取顺序与存储写入顺序一致。
+func (b *eventBus) ticket(instanceID int64) (prev, mine chan struct{}) {
+	b.seqMu.Lock()
+	defer b.seqMu.Unlock()
+	if b.tails == nil {
+		b.tails = map[int64]chan struct{}{}
+	}
+	prev, mine = b.tails[instanceID], make(chan struct{})
+	b.tails[instanceID] = mine
+	return prev, mine
+}
+
+// release 标记本次投递完成，并在其为该实例最后一个事件时清理记录。
+func (b *eventBus) release(instanceID int64, mine chan struct{}) {
+	close(mine)
+	b.seqMu.Lock()
+	defer b.seqMu.Unlock()
+	if b.tails[instanceID] == mine {
+		delete(b.tails, instanceID)
+	}
+}
+
+// publish 在读锁内取匹配的订阅者快照，释放锁后再投递，阻塞中的投递不会拖住订阅与注销。
+func (b *eventBus) publish(ev InstanceEvent) {
+	b.mu.RLock()
+	subs := make([]*subscriber, 0, len(b.subs))
+	for s := range b.subs {
+		if s.filter.Match(&ev) {
+			subs = append(subs, s)
+		}
+	}
+	b.mu.RUnlock()
+	for _, s := range subs {
+		s.push(ev)
+	}
+}
+
+// subscriber 单个订阅者：mu 串行化投递与关闭，避免向已关闭的 channel 发送。
+type subscriber struct {
+	filter  EventFilter
+	policy  Backpressure
+	ch      chan InstanceEvent
+	done    chan struct{}
+	mu      sync.Mutex
+	closed  bool
+	dropped uint64
+}
+
+func (s *subscriber) push(ev InstanceEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	ev.Dropped = s.dropped
+	select {
+	case s.ch <- ev:
+		return
+	default:
+	}
+	switch s.policy {
+	case Block:
+		select {
+		case s.ch <- ev:
+		case <-s.done:
+		}
+	case DropOldest:
+		select {
+		case <-s.ch:
+			s.dropped++
+		default:
+		}
+		ev.Dropped = s.dropped
+		select {
+		case s.ch <- ev:
+		default:
+			s.dropped++
+		}
+	default:
+		s.dropped++
+	}
+}
+
+func (s *subscriber) close() {
+	close(s.done) // 先唤醒阻塞中的投递，再获取锁关闭 channel
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+// transition 在实例锁内执行状态写入，释放锁后在后台发布事件，保证同一实例的事件顺序与存储一致；
+// 调用方（stopInstance、状态上报、处理器进度回调等）不会被慢订阅者阻塞。
+// 参数：typ 事件类型；write 实际的存储写入，返回错误时不发布事件。
+func (w *Worker) transition(ctx context.Context, instanceID int64, typ EventType, write func() error) error {
+	mu := w.bus.lock(instanceID)
+	mu.Lock()
+	deliver, err := w.transitionLocked(ctx, instanceID, typ, write)
+	mu.Unlock()
+	go deliver()
+	return err
+}
+
+// transitionLocked 同 transition，调用方需已持有 w.bus.lock(instanceID)。
+// 返回：deliver 必须在释放实例锁之后调用，Block 策略下可能阻塞，请求路径上应以 goroutine 执行；
+// 写入失败或无订阅者时为空操作。
+func (w *Worker) transitionLocked(ctx context.Context, instanceID int64, typ EventType, write func() error) (deliver func(), err error) {
+	if err := write(); err != nil {
+		return func() {}, err
+	}
+	if !w.bus.active() {
+		return func() {}, nil
+	}
+	rec, err := w.store.Get(ctx, instanceID)
+	if err != nil {
+		return func() {}, nil
+	}
+	ev := InstanceEvent{Type: typ, Record: *rec, At: time.Now()}
+	prev, mine := w.bus.ticket(instanceID)
+	return func() {
+		if prev != nil {
+			<-prev
+		}
+		w.bus.publish(ev)
+		w.bus.release(instanceID, mine)
+	}, nil
+}
+
+// errUnchanged 写入函数用于表示“无需变更”，transition 据此跳过发布。
+var errUnchanged = errors.New("unchanged")
+
+// eventTypeOf 将终态映射为事件类型。
+func eventTypeOf(status int) EventType {
+	switch status {
+	case StateSucceed:
+		return EventSucceeded
+	case StateStopped, StateCanceled:
+		return EventStopped
+	default:
+		return EventFailed
+	}
+}
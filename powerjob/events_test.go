@@ -0,0 +1,242 @@
+package powerjob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// collect 在 d 时间内读取事件，channel 关闭时提前返回。
+func collect(ch <-chan InstanceEvent, d time.Duration) []InstanceEvent {
+	var out []InstanceEvent
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return out
+			}
+			out = append(out, ev)
+		case <-timer.C:
+			return out
+		}
+	}
+}
+
+func typesOf(evs []InstanceEvent) []EventType {
+	out := make([]EventType, 0, len(evs))
+	for _, ev := range evs {
+		out = append(out, ev.Type)
+	}
+	return out
+}
+
+func TestWorker_Subscribe(t *testing.T) {
+	Convey("subscribers should receive ordered lifecycle events with record snapshots", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("events.ok", func(ctx context.Context, in string) (string, error) {
+			processor.SetProgress(ctx, 50)
+			processor.SetProgress(ctx, 50) // 未变化，不产生事件
+			return "done", nil
+		}))
+		api := &recordAPI{}
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("ev"), WithListenAddr("127.0.0.1:0"), WithClientAPI(api),
+			WithIntervals(time.Second, time.Second, 30*time.Second))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		subCtx, unsub := context.WithCancel(ctx)
+		all := w.Subscribe(subCtx, EventFilter{InstanceID: 81})
+		done := w.Subscribe(subCtx, EventFilter{Types: []EventType{EventSucceeded}})
+
+		b, _ := json.Marshal(client.ServerScheduleJobReq{InstanceID: 81, JobID: 8, ProcessorInfo: "events.ok"})
+		resp, err := http.Post("http://"+w.Addr()+"/worker/runJob", "application/json", bytes.NewReader(b))
+		So(err, ShouldBeNil)
+		_ = resp.Body.Close()
+
+		evs := collect(all, 2200*time.Millisecond)
+		So(typesOf(evs), ShouldResemble, []EventType{EventStarted, EventProgress, EventSucceeded, EventReported})
+		So(evs[0].Record.Status, ShouldEqual, StateRunning)
+		So(evs[1].Record.Progress, ShouldEqual, 50)
+		So(evs[2].Record.ResultMsg, ShouldEqual, "done")
+		So(evs[3].Record.Reported, ShouldBeTrue)
+
+		fin := collect(done, 10*time.Millisecond)
+		So(len(fin), ShouldEqual, 1)
+		So(fin[0].Record.InstanceID, ShouldEqual, 81)
+
+		unsub()
+		_, open := <-all
+		So(open, ShouldBeFalse)
+	})
+}
+
+func TestWorker_SubscribeBlockDoesNotStallDispatch(t *testing.T) {
+	Convey("a stuck Block subscriber should not delay dispatches that share its lock stripe", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("events.fast", func(ctx context.Context, in string) (string, error) { return "ok", nil }))
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("ev"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&recordAPI{}))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		evs := w.Subscribe(ctx, EventFilter{}, WithEventBuffer(1), WithBackpressure(Block))
+		start := time.Now()
+		for _, id := range []int64{1, 33, 65} { // 同一分片
+			b, _ := json.Marshal(client.ServerScheduleJobReq{InstanceID: id, JobID: 1, ProcessorInfo: "events.fast"})
+			resp, err := http.Post("http://"+w.Addr()+"/worker/runJob", "application/json", bytes.NewReader(b))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			_ = resp.Body.Close()
+		}
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+
+		got := map[int64][]EventType{}
+		for _, ev := range collect(evs, 300*time.Millisecond) {
+			got[ev.Record.InstanceID] = append(got[ev.Record.InstanceID], ev.Type)
+		}
+		for _, id := range []int64{1, 33, 65} {
+			So(got[id], ShouldResemble, []EventType{EventStarted, EventSucceeded})
+		}
+	})
+}
+
+func TestWorker_SubscribeBlockDoesNotStallStop(t *testing.T) {
+	Convey("a stuck Block subscriber should not delay stopInstance or other subscriptions", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("events.hold", func(ctx context.Context, in string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}))
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("ev"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&recordAPI{}))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		_ = w.Subscribe(ctx, EventFilter{}, WithEventBuffer(1), WithBackpressure(Block)) // 从不读取
+		post := func(path string, v any) {
+			b, _ := json.Marshal(v)
+			resp, err := http.Post("http://"+w.Addr()+path, "application/json", bytes.NewReader(b))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			_ = resp.Body.Close()
+		}
+		for _, id := range []int64{95, 96} {
+			post("/worker/runJob", client.ServerScheduleJobReq{InstanceID: id, JobID: 9, ProcessorInfo: "events.hold"})
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		start := time.Now()
+		post("/worker/stopInstance", map[string]int64{"instanceId": 95})
+		post("/worker/stopInstance", map[string]int64{"instanceId": 96})
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+
+		subCtx, unsub := context.WithCancel(ctx)
+		other := w.Subscribe(subCtx, EventFilter{})
+		unsub()
+		closed := make(chan struct{})
+		go func() {
+			for range other {
+			}
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			So("subscription cancel blocked by a stuck subscriber", ShouldBeEmpty)
+		}
+	})
+}
+
+func TestWorker_SubscribeStopped(t *testing.T) {
+	Convey("a stopped instance should end STOPPED with a single terminal event", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("events.wait", func(ctx context.Context, in string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err() // 处理器把取消当作错误返回，不应被记为 FAILED
+		}))
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("ev"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&recordAPI{}))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		evs := w.Subscribe(ctx, EventFilter{InstanceID: 91})
+		post := func(path string, v any) {
+			b, _ := json.Marshal(v)
+			resp, err := http.Post("http://"+w.Addr()+path, "application/json", bytes.NewReader(b))
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusOK)
+			_ = resp.Body.Close()
+		}
+		post("/worker/runJob", client.ServerScheduleJobReq{InstanceID: 91, JobID: 9, ProcessorInfo: "events.wait"})
+		time.Sleep(20 * time.Millisecond)
+		post("/worker/stopInstance", map[string]int64{"instanceId": 91})
+
+		So(typesOf(collect(evs, 200*time.Millisecond)), ShouldResemble, []EventType{EventStarted, EventStopped})
+		rec, err := w.store.Get(ctx, 91)
+		So(err, ShouldBeNil)
+		So(rec.Status, ShouldEqual, StateStopped)
+	})
+}
+
+func TestSubscriber_Backpressure(t *testing.T) {
+	Convey("full buffers should drop according to policy and report gaps", t, func() {
+		ev := func(id int64) InstanceEvent { return InstanceEvent{Type: EventStarted, Record: InstanceRecord{InstanceID: id}} }
+
+		Convey("DropNewest keeps the earliest events", func() {
+			s := &subscriber{ch: make(chan InstanceEvent, 2), done: make(chan struct{})}
+			for i := int64(1); i <= 4; i++ {
+				s.push(ev(i))
+			}
+			So(s.dropped, ShouldEqual, 2)
+			So((<-s.ch).Record.InstanceID, ShouldEqual, 1)
+			So((<-s.ch).Record.InstanceID, ShouldEqual, 2)
+			s.push(ev(5))
+			got := <-s.ch
+			So(got.Record.InstanceID, ShouldEqual, 5)
+			So(got.Dropped, ShouldEqual, 2)
+		})
+
+		Convey("DropOldest keeps the latest events in order", func() {
+			s := &subscriber{ch: make(chan InstanceEvent, 2), done: make(chan struct{}), policy: DropOldest}
+			for i := int64(1); i <= 4; i++ {
+				s.push(ev(i))
+			}
+			So(s.dropped, ShouldEqual, 2)
+			So((<-s.ch).Record.InstanceID, ShouldEqual, 3)
+			So((<-s.ch).Record.InstanceID, ShouldEqual, 4)
+		})
+
+		Convey("Block waits for the consumer and is released on close", func() {
+			s := &subscriber{ch: make(chan InstanceEvent, 1), done: make(chan struct{}), policy: Block}
+			s.push(ev(1))
+			released := make(chan struct{})
+			go func() { s.push(ev(2)); close(released) }()
+			time.Sleep(20 * time.Millisecond)
+			So((<-s.ch).Record.InstanceID, ShouldEqual, 1)
+			<-released
+			So((<-s.ch).Record.InstanceID, ShouldEqual, 2)
+
+			s.push(ev(3))
+			blocked := make(chan struct{})
+			go func() { s.push(ev(4)); close(blocked) }()
+			time.Sleep(20 * time.Millisecond)
+			s.close()
+			<-blocked
+			So(s.dropped, ShouldEqual, 0)
+		})
+	})
+}
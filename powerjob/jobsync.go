@@ -0,0 +1,38 @@
+package powerjob
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mengeric/powerjob-client-go/jobsync"
+	"github.com/mengeric/powerjob-client-go/logging"
+)
+
+// WithJobSync 启用 jobs as code：Start 时把注册表中处理器声明的任务定义（processor.JobDefinition）
+// 经 api（通常为 *openapi.Client）同步到 Server；传入 jobsync.DryRun() 时只输出计划不提交。
+// 同步假定只有一个写入方：多副本部署时应只在一个副本上提交（其它副本使用 DryRun 或不启用），
+// 否则同时启动的副本可能重复新建同一任务。
+func WithJobSync(api jobsync.JobAPI, opts ...jobsync.Option) Option {
+	return func(c *workerConfig) { c.sync = jobsync.New(api, opts...) }
+}
+
+// syncJobs 执行任务定义同步并逐行输出计划；失败只记录日志，不影响 Worker 启动。
+func (w *Worker) syncJobs(ctx context.Context) {
+	defs := w.reg.Definitions()
+	if len(defs) == 0 {
+		return
+	}
+	plan, err := w.sync.Run(ctx, defs)
+	mode := "applied"
+	if w.sync.DryRun() {
+		mode = "dry-run"
+	}
+	for _, line := range strings.Split(strings.TrimRight(plan.String(), "\n"), "\n") {
+		if line != "" {
+			logging.L().Infof(ctx, "job sync (%s): %s", mode, line)
+		}
+	}
+	if err != nil {
+		logging.L().Errorf(ctx, "job sync failed: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package powerjob
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/jobsync"
+	"github.com/mengeric/powerjob-client-go/openapi"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// syncAPI 记录同步时提交的任务。
+type syncAPI struct{ saved chan openapi.SaveJobRequest }
+
+func (s syncAPI) FetchAllJobs(ctx context.Context) ([]openapi.JobInfo, error) { return nil, nil }
+
+func (s syncAPI) SaveJob(ctx context.Context, req openapi.SaveJobRequest) (int64, error) {
+	s.saved <- req
+	return 1, nil
+}
+
+func TestWorker_JobSync(t *testing.T) {
+	Convey("Start should sync declared job definitions when enabled", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("sync.job", func(ctx context.Context, in string) (string, error) { return in, nil }))
+		reg.Define("sync.job", processor.JobDefinition{Cron: "0 0 3 * * ?", Retries: 1})
+		api := syncAPI{saved: make(chan openapi.SaveJobRequest, 4)}
+		start := func(opts ...jobsync.Option) {
+			w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("sync"), WithListenAddr("127.0.0.1:0"),
+				WithClientAPI(&dummyAPI{}), WithJobSync(api, opts...))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			w.Start(ctx)
+		}
+
+		start()
+		var got openapi.SaveJobRequest
+		select {
+		case got = <-api.saved:
+		case <-time.After(time.Second):
+		}
+		So(got.ProcessorInfo, ShouldEqual, "sync.job")
+		So(got.TimeExpression, ShouldEqual, "0 0 3 * * ?")
+		So(got.InstanceRetryNum, ShouldEqual, 1)
+
+		start(jobsync.DryRun())
+		So(len(api.saved), ShouldEqual, 0)
+	})
+}
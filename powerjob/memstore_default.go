@@ -2,27 +2,25 @@ package powerjob
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"time"
 )
 
-// inMemoryStore 是包内置的线程安全内存存储，仅用于默认与测试场景。
-// 设计：为了避免 import cycle，不依赖外部子包，实现最小的 Storage 接口。
-type inMemoryStore struct {
+// MemoryStore 线程安全的内存存储，是 Worker 的默认存储，也是 storage/memstore 的唯一实现。
+// 实现 Storage 及 NotFoundReporter、PendingReportLister、Pruner、InstanceQuerier 扩展接口；进程重启后数据丢失。
+type MemoryStore struct {
 	mu sync.RWMutex
 	m  map[int64]*InstanceRecord
 }
 
-// newDefaultMemStore 创建内置内存存储实现。
-// 返回：满足 Storage 的实现。
-func newDefaultMemStore() Storage { return &inMemoryStore{m: map[int64]*InstanceRecord{}} }
+// NewMemoryStore 创建内存存储。
+func NewMemoryStore() *MemoryStore { return &MemoryStore{m: map[int64]*InstanceRecord{}} }
 
 // Upsert 插入或更新实例记录。
 // 参数：
 // - rec：实例记录指针；若不存在则插入，存在则按 instanceId 覆盖；
 // 返回：错误信息；正常情况返回 nil。
-func (s *inMemoryStore) Upsert(ctx context.Context, rec *InstanceRecord) error {
+func (s *MemoryStore) Upsert(ctx context.Context, rec *InstanceRecord) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	cp := *rec
@@ -37,7 +35,7 @@ func (s *inMemoryStore) Upsert(ctx context.Context, rec *InstanceRecord) error {
 }
 
 // UpdateStatus 更新实例状态。
-func (s *inMemoryStore) UpdateStatus(ctx context.Context, instanceID int64, status int, resultCode int, resultMsg string) error {
+func (s *MemoryStore) UpdateStatus(ctx context.Context, instanceID int64, status int, resultCode int, resultMsg string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if r, ok := s.m[instanceID]; ok {
@@ -47,22 +45,25 @@ func (s *inMemoryStore) UpdateStatus(ctx context.Context, instanceID int64, stat
 		r.UpdatedAt = time.Now()
 		return nil
 	}
-	return errors.New("not found")
+	return ErrNotFound
 }
 
+// ReportsNotFound 实现 NotFoundReporter：记录不存在时 Get 返回 ErrNotFound。
+func (s *MemoryStore) ReportsNotFound() bool { return true }
+
 // Get 按 instanceID 读取记录。
-func (s *inMemoryStore) Get(ctx context.Context, instanceID int64) (*InstanceRecord, error) {
+func (s *MemoryStore) Get(ctx context.Context, instanceID int64) (*InstanceRecord, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	if r, ok := s.m[instanceID]; ok {
 		cp := *r
 		return &cp, nil
 	}
-	return nil, errors.New("not found")
+	return nil, ErrNotFound
 }
 
 // ListRunning 列出运行中实例。
-func (s *inMemoryStore) ListRunning(ctx context.Context) ([]InstanceRecord, error) {
+func (s *MemoryStore) ListRunning(ctx context.Context) ([]InstanceRecord, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	out := make([]InstanceRecord, 0)
@@ -73,3 +74,52 @@ func (s *inMemoryStore) ListRunning(ctx context.Context) ([]InstanceRecord, erro
 	}
 	return out, nil
 }
+
+// ListPendingReport 列出已终态但尚未上报成功的实例。
+func (s *MemoryStore) ListPendingReport(ctx context.Context) ([]InstanceRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]InstanceRecord, 0)
+	for _, v := range s.m {
+		if IsTerminal(v.Status) && !v.Reported {
+			out = append(out, *v)
+		}
+	}
+	return out, nil
+}
+
+// Delete 删除实例记录。
+func (s *MemoryStore) Delete(ctx context.Context, instanceID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, instanceID)
+	return nil
+}
+
+// Prune 按保留策略淘汰已结束且已上报的记录。
+func (s *MemoryStore) Prune(ctx context.Context, p RetentionPolicy) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := make([]InstanceRecord, 0, len(s.m))
+	for _, v := range s.m {
+		all = append(all, *v)
+	}
+	ids := SelectPrunable(all, p, time.Now())
+	for _, id := range ids {
+		delete(s.m, id)
+	}
+	return len(ids), nil
+}
+
+// ListInstances 按条件分页查询实例记录。
+func (s *MemoryStore) ListInstances(ctx context.Context, q InstanceQuery) (InstancePage, error) {
+	s.mu.RLock()
+	out := make([]InstanceRecord, 0)
+	for _, v := range s.m {
+		if q.Match(v) {
+			out = append(out, *v)
+		}
+	}
+	s.mu.RUnlock()
+	return q.Paginate(out), nil
+}
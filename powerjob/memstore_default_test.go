@@ -0,0 +1,12 @@
+package powerjob_test
+
+import (
+	"testing"
+
+	"github.com/mengeric/powerjob-client-go/powerjob"
+	"github.com/mengeric/powerjob-client-go/storage/storagetest"
+)
+
+func TestMemoryStore_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) powerjob.Storage { return powerjob.NewMemoryStore() })
+}
@@ -0,0 +1,24 @@
+package powerjob
+
+import "net/http"
+
+// WorkerMetrics Worker 运行期计数快照。
+type WorkerMetrics struct {
+	Rejected          map[RejectReason]int64 // 各拒绝原因的 runJob 次数
+	UnknownProcessors map[string]int64       // 因处理器未注册被拒绝的 processorInfo 及次数，最多 100 个标签，其余计入 "other"
+}
+
+// Metrics 返回当前计数快照，可对接 Prometheus 等监控系统。
+func (w *Worker) Metrics() WorkerMetrics {
+	out := WorkerMetrics{Rejected: map[RejectReason]int64{}, UnknownProcessors: w.unknown.Snapshot()}
+	for k, v := range w.rejects.Snapshot() {
+		out.Rejected[RejectReason(k)] = v
+	}
+	return out
+}
+
+// reject 记录拒绝计数并写回响应。
+func (w *Worker) reject(rw http.ResponseWriter, reason RejectReason, msg string, data any) {
+	w.rejects.Inc(string(reason))
+	writeReject(rw, reason, msg, data)
+}
@@ -1,24 +1,36 @@
 package powerjob
 
 import (
+	"crypto/tls"
+
+	"github.com/mengeric/powerjob-client-go/auth"
 	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/jobsync"
+	"github.com/mengeric/powerjob-client-go/processor"
 	"time"
 )
 
 // Options 组件运行参数。
 // 功能：描述与 PowerJob-Server 的交互周期、监听端口、在线日志等行为；
-// 说明：组件会在 Start(ctx) 内部启动内置 HTTP Server（监听 ListenAddr）。
+// 说明：组件会在 Start(ctx) 内部启动内置 HTTP Server（监听 ListenAddr），通过 Worker.Handler 挂载时除外。
 type Options struct {
-	ListenAddr      string        // HTTP 服务监听地址，例如 :27777 或 127.0.0.1:0（0 表示随机端口）
-	BootstrapServer string        // 引导地址，如 127.0.0.1:7700
-	AppName         string        // 应用名
-	ClientVersion   string        // 客户端版本
-	HeartbeatEvery  time.Duration // 心跳上报周期
-	ReportEvery     time.Duration // 实例状态上报周期
-	DiscoveryEvery  time.Duration // 服务发现刷新周期
-	WorkerAddress   string        // 向 Server 上报的 workerAddress（外部可见地址）
-	LogReportEvery  time.Duration // 在线日志上报周期
-	LogBatchSize    int           // 在线日志单批最大条数
+	ListenAddr      string          // HTTP 服务监听地址，例如 :27777 或 127.0.0.1:0（0 表示随机端口）；通过 Worker.Handler 挂载时忽略
+	BootstrapServer string          // 引导地址，如 127.0.0.1:7700
+	AppName         string          // 应用名
+	ClientVersion   string          // 客户端版本
+	HeartbeatEvery  time.Duration   // 心跳上报周期
+	ReportEvery     time.Duration   // 实例状态上报周期
+	DiscoveryEvery  time.Duration   // 服务发现刷新周期
+	WorkerAddress   string          // 向 Server 上报的 workerAddress（外部可见地址）
+	LogReportEvery  time.Duration   // 在线日志上报周期
+	LogBatchSize    int             // 在线日志单批最大条数
+	Retention       RetentionPolicy // 已结束实例记录保留策略，零值时使用默认（24h / 10000 条）
+	RetentionEvery  time.Duration   // 记录清理周期，默认 1 分钟；负数表示关闭清理
+	DedupeWindow    time.Duration   // 已结束实例的重复派发去重窗口，默认 24 小时；负数表示仅对运行中实例去重
+	ReportKeepAlive time.Duration   // 状态与进度未变化的运行中实例的保活上报间隔，默认 30s；负数表示每个周期都上报
+
+	MaxConcurrentInstances int // 同时运行的实例上限，超出时 runJob 以 OVERLOADED 拒绝；<=0 表示不限制
+	ReportParallelism      int // Server 不支持批量上报时逐个上报状态的最大并发数，默认 8
 }
 
 // withDefaults 填充默认值。
@@ -41,15 +53,36 @@ func (o *Options) withDefaults() {
 	if o.LogBatchSize <= 0 {
 		o.LogBatchSize = 256
 	}
+	if o.Retention == (RetentionPolicy{}) {
+		o.Retention = RetentionPolicy{MaxAge: 24 * time.Hour, MaxCount: 10000}
+	}
+	if o.RetentionEvery == 0 {
+		o.RetentionEvery = time.Minute
+	}
+	if o.DedupeWindow == 0 {
+		o.DedupeWindow = 24 * time.Hour
+	}
 }
 
 // Option 函数式可选项，用于构造 Worker。
 type Option func(*workerConfig)
 
 type workerConfig struct {
-    opt   Options
-    store Storage
-    api   client.ServerAPI
+	opt   Options
+	store Storage
+	api   client.ServerAPI
+	reg   *processor.Registry
+	mws   []processor.Middleware
+	keyMW map[string][]processor.Middleware
+
+	srvTLS  *tls.Config
+	cliOpts []client.ClientOption
+
+	authn auth.Authenticator
+	allow *auth.IPAllowList
+	audit auth.AuditFunc
+
+	sync *jobsync.Reconciler
 }
 
 // WithOptions 批量设置运行参数。
@@ -75,13 +108,78 @@ func WithIntervals(hb, rep, disc time.Duration) Option {
 	return func(c *workerConfig) { c.opt.HeartbeatEvery, c.opt.ReportEvery, c.opt.DiscoveryEvery = hb, rep, disc }
 }
 
+// WithStatusReporter 配置实例状态上报：keepAlive 为未变化实例的保活间隔（负数表示每个周期都上报），
+// parallelism 为 Server 不支持批量上报时的最大并发数；传 0 保持默认。
+func WithStatusReporter(keepAlive time.Duration, parallelism int) Option {
+	return func(c *workerConfig) { c.opt.ReportKeepAlive, c.opt.ReportParallelism = keepAlive, parallelism }
+}
+
 // WithLogReporter 配置在线日志上报参数。
 func WithLogReporter(every time.Duration, batch int) Option {
 	return func(c *workerConfig) { c.opt.LogReportEvery, c.opt.LogBatchSize = every, batch }
 }
 
-// withStore 仅测试或高级接入使用：替换默认内存存储。
-func withStore(s Storage) Option { return func(c *workerConfig) { c.store = s } }
+// WithRetention 配置已结束实例记录的保留策略与清理周期（every 为负数时关闭清理）。
+func WithRetention(p RetentionPolicy, every time.Duration) Option {
+	return func(c *workerConfig) { c.opt.Retention, c.opt.RetentionEvery = p, every }
+}
+
+// WithDedupeWindow 设置已结束实例的重复派发去重窗口（负数表示仅对运行中实例去重）。
+// 说明：窗口取决于存储中记录的保留时长，需配合 Retention 使用，超出保留期的记录无法去重。
+func WithDedupeWindow(d time.Duration) Option {
+	return func(c *workerConfig) { c.opt.DedupeWindow = d }
+}
+
+// WithMaxConcurrentInstances 设置同时运行的实例上限（<=0 表示不限制）。
+func WithMaxConcurrentInstances(n int) Option {
+	return func(c *workerConfig) { c.opt.MaxConcurrentInstances = n }
+}
+
+// WithStorage 替换默认内存存储，例如使用 storage/filestore 持久化实例记录以便重启恢复。
+func WithStorage(s Storage) Option { return func(c *workerConfig) { c.store = s } }
+
+// WithServerTLS 以 TLS 提供内置 HTTP 服务；cfg 可由 tlsutil.Reloader.ServerConfig 生成以支持证书热更新与双向 TLS。
+// 说明：通过 Worker.Handler 挂载时由宿主服务负责 TLS，该选项不生效。
+func WithServerTLS(cfg *tls.Config) Option { return func(c *workerConfig) { c.srvTLS = cfg } }
+
+// WithClientTLS 以 HTTPS 访问 PowerJob Server（自定义 CA、客户端证书）；使用 WithClientAPI 时不生效。
+func WithClientTLS(cfg *tls.Config) Option {
+	return func(c *workerConfig) { c.cliOpts = append(c.cliOpts, client.WithTLSConfig(cfg)) }
+}
+
+// WithClientOptions 定制默认 ServerAPI 的 HTTP 传输（http.Client、超时、请求头、埋点 Hook 等）；使用 WithClientAPI 时不生效。
+func WithClientOptions(opts ...client.ClientOption) Option {
+	return func(c *workerConfig) { c.cliOpts = append(c.cliOpts, opts...) }
+}
+
+// WithAuthenticator 为全部 Worker 端点启用鉴权（如 auth.HMAC、auth.Bearer 及其组合），未通过的请求返回 401/403。
+func WithAuthenticator(a auth.Authenticator) Option { return func(c *workerConfig) { c.authn = a } }
+
+// WithServerAllowList 按来源 IP 限制 Worker 端点的调用方：在 a 的静态 IP/CIDR 之外，
+// 自动放行 Bootstrap 地址与服务发现得到的 Server 地址（旧地址 10 分钟后过期）；与 WithAuthenticator 同时配置时两者都需通过。
+func WithServerAllowList(a *auth.IPAllowList) Option { return func(c *workerConfig) { c.allow = a } }
+
+// WithAuditLog 指定被拒绝请求的审计日志输出；默认以 WARN 级别写入组件日志。
+func WithAuditLog(fn auth.AuditFunc) Option { return func(c *workerConfig) { c.audit = fn } }
 
 // WithClientAPI 替换默认 ServerAPI（测试场景使用）。
 func WithClientAPI(api client.ServerAPI) Option { return func(c *workerConfig) { c.api = api } }
+
+// WithRegistry 指定 Worker 使用的处理器注册表；不设置时使用 processor.Default。
+// 同进程运行多个应用时，为每个 Worker 传入独立的 Registry 即可隔离同名 key。
+func WithRegistry(r *processor.Registry) Option { return func(c *workerConfig) { c.reg = r } }
+
+// WithMiddleware 追加对所有处理器生效的中间件，先追加的位于外层。
+func WithMiddleware(mws ...processor.Middleware) Option {
+	return func(c *workerConfig) { c.mws = append(c.mws, mws...) }
+}
+
+// WithProcessorMiddleware 追加仅对指定处理器 key 生效的中间件，位于全局中间件之内。
+func WithProcessorMiddleware(key string, mws ...processor.Middleware) Option {
+	return func(c *workerConfig) {
+		if c.keyMW == nil {
+			c.keyMW = map[string][]processor.Middleware{}
+		}
+		c.keyMW[key] = append(c.keyMW[key], mws...)
+	}
+}
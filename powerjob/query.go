@@ -0,0 +1,122 @@
+package powerjob
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// 分页默认值与上限。
+const (
+	DefaultQueryLimit = 50
+	MaxQueryLimit     = 1000
+)
+
+// InstanceQuery 实例记录过滤与分页条件；零值字段表示不按该维度过滤。
+// 时间窗口为左闭右开区间 [From, To)。
+type InstanceQuery struct {
+	JobID       int64     `json:"jobId,omitempty"`
+	Statuses    []int     `json:"statuses,omitempty"`
+	StartedFrom time.Time `json:"startedFrom,omitzero"`
+	StartedTo   time.Time `json:"startedTo,omitzero"`
+	UpdatedFrom time.Time `json:"updatedFrom,omitzero"`
+	UpdatedTo   time.Time `json:"updatedTo,omitzero"`
+	Offset      int       `json:"offset,omitempty"`
+	Limit       int       `json:"limit,omitempty"` // <=0 时取 DefaultQueryLimit，超过 MaxQueryLimit 时截断
+}
+
+// InstancePage 分页结果：Items 按 StartedAt、InstanceID 倒序；Total 为过滤后的总条数。
+type InstancePage struct {
+	Items []InstanceRecord `json:"items"`
+	Total int              `json:"total"`
+}
+
+// InstanceQuerier 可选扩展：支持按条件分页查询实例记录。
+// 未实现时 QueryInstances 退化为在 ListRunning（及 PendingReportLister）结果上过滤。
+type InstanceQuerier interface {
+	ListInstances(ctx context.Context, q InstanceQuery) (InstancePage, error)
+}
+
+// Match 判断记录是否满足过滤条件。
+func (q InstanceQuery) Match(r *InstanceRecord) bool {
+	if q.JobID != 0 && r.JobID != q.JobID {
+		return false
+	}
+	if len(q.Statuses) > 0 {
+		hit := false
+		for _, st := range q.Statuses {
+			if r.Status == st {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			return false
+		}
+	}
+	return inWindow(r.StartedAt, q.StartedFrom, q.StartedTo) && inWindow(r.UpdatedAt, q.UpdatedFrom, q.UpdatedTo)
+}
+
+// Paginate 对已过滤的记录排序并截取当前页，供各 Storage 实现复用。
+func (q InstanceQuery) Paginate(recs []InstanceRecord) InstancePage {
+	sort.Slice(recs, func(i, j int) bool {
+		if !recs[i].StartedAt.Equal(recs[j].StartedAt) {
+			return recs[i].StartedAt.After(recs[j].StartedAt)
+		}
+		return recs[i].InstanceID > recs[j].InstanceID
+	})
+	page := InstancePage{Items: []InstanceRecord{}, Total: len(recs)}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	if limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+	if q.Offset < 0 || q.Offset >= len(recs) {
+		return page
+	}
+	end := q.Offset + limit
+	if end > len(recs) {
+		end = len(recs)
+	}
+	page.Items = append(page.Items, recs[q.Offset:end]...)
+	return page
+}
+
+// QueryInstances 按条件分页查询实例记录。
+// 存储实现 InstanceQuerier 时直接委托；否则仅能覆盖运行中与待上报的记录。
+func QueryInstances(ctx context.Context, s Storage, q InstanceQuery) (InstancePage, error) {
+	if qs, ok := s.(InstanceQuerier); ok {
+		return qs.ListInstances(ctx, q)
+	}
+	recs, err := s.ListRunning(ctx)
+	if err != nil {
+		return InstancePage{}, err
+	}
+	if pl, ok := s.(PendingReportLister); ok {
+		pending, err := pl.ListPendingReport(ctx)
+		if err != nil {
+			return InstancePage{}, err
+		}
+		recs = append(recs, pending...)
+	}
+	out := make([]InstanceRecord, 0, len(recs))
+	for i := range recs {
+		if q.Match(&recs[i]) {
+			out = append(out, recs[i])
+		}
+	}
+	return q.Paginate(out), nil
+}
+
+// inWindow 判断 t 是否位于 [from, to)，零值边界表示不限。
+func inWindow(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !t.Before(to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package powerjob
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestQueryInstances(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := func(s Storage) {
+		for i := int64(1); i <= 6; i++ {
+			st := StateSucceed
+			if i%2 == 0 {
+				st = StateFailed
+			}
+			job := int64(42)
+			if i == 6 {
+				job = 7
+			}
+			at := base.Add(time.Duration(i) * time.Hour)
+			_ = s.Upsert(ctx, &InstanceRecord{InstanceID: i, JobID: job, Status: st, StartedAt: at, UpdatedAt: at})
+		}
+		_ = s.Upsert(ctx, &InstanceRecord{InstanceID: 7, JobID: 42, Status: StateRunning, StartedAt: base.Add(7 * time.Hour), UpdatedAt: base.Add(7 * time.Hour)})
+	}
+
+	Convey("in-memory store should filter by job, status and time window with pagination", t, func() {
+		s := NewMemoryStore()
+		seed(s)
+
+		page, err := QueryInstances(ctx, s, InstanceQuery{JobID: 42})
+		So(err, ShouldBeNil)
+		So(page.Total, ShouldEqual, 6)
+		So(page.Items[0].InstanceID, ShouldEqual, 7) // 最新在前
+
+		page, _ = QueryInstances(ctx, s, InstanceQuery{JobID: 42, Statuses: []int{StateFailed}})
+		So(page.Total, ShouldEqual, 2)
+
+		page, _ = QueryInstances(ctx, s, InstanceQuery{StartedFrom: base.Add(2 * time.Hour), StartedTo: base.Add(5 * time.Hour)})
+		So(page.Total, ShouldEqual, 3)
+
+		page, _ = QueryInstances(ctx, s, InstanceQuery{JobID: 42, Offset: 2, Limit: 2})
+		So(page.Total, ShouldEqual, 6)
+		So(len(page.Items), ShouldEqual, 2)
+		So(page.Items[0].InstanceID, ShouldEqual, 4)
+		So(page.Items[1].InstanceID, ShouldEqual, 3)
+
+		page, _ = QueryInstances(ctx, s, InstanceQuery{Offset: 100})
+		So(page.Items, ShouldBeEmpty)
+	})
+
+	Convey("legacy storages without InstanceQuerier should fall back to running records", t, func() {
+		s := &memStore{}
+		seed(s)
+		page, err := QueryInstances(ctx, s, InstanceQuery{JobID: 42})
+		So(err, ShouldBeNil)
+		So(page.Total, ShouldEqual, 1)
+		So(page.Items[0].InstanceID, ShouldEqual, 7)
+	})
+
+	Convey("ListInstances is a Go API only and is not served over HTTP", t, func() {
+		w := NewWorker(WithBootstrapServer("x"), WithAppName("demo"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&recordAPI{}))
+		seed(w.store)
+		page, err := w.ListInstances(ctx, InstanceQuery{JobID: 7})
+		So(err, ShouldBeNil)
+		So(page.Total, ShouldEqual, 1)
+		So(page.Items[0].InstanceID, ShouldEqual, 6)
+
+		rec := httptest.NewRecorder()
+		w.Handler("").ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/worker/listInstances", strings.NewReader(`{}`)))
+		So(rec.Code, ShouldEqual, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,54 @@
+package powerjob
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// RejectReason runJob 被拒绝的原因，写入 AskResponse.Reason（扩展字段）与 Message 前缀，便于 Server 与运维区分处理。
+type RejectReason string
+
+const (
+	RejectUnknownProcessor RejectReason = "UNKNOWN_PROCESSOR" // 处理器未注册
+	RejectOverloaded       RejectReason = "OVERLOADED"        // 运行中实例数已达 MaxConcurrentInstances
+	RejectDuplicate        RejectReason = "DUPLICATE"         // 重复派发（运行中或去重窗口内已结束），详见 Data
+	RejectShuttingDown     RejectReason = "SHUTTING_DOWN"     // Worker 正在关闭
+)
+
+// AskResponse Worker 端点统一响应，与 PowerJob AskResponse 协议对齐：
+// success 表示请求是否被受理，message 为可读描述，data 为端点相关的结果。
+// 说明：reason 是本组件的扩展字段，不属于 PowerJob 协议，标准 Server 会忽略它；
+// 拒绝原因同时以 "REASON: 描述" 的形式写在 message 开头，只解析标准字段的调用方也能区分。
+type AskResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Data    any          `json:"data,omitempty"`
+	Reason  RejectReason `json:"reason,omitempty"` // 扩展字段，仅 success=false 的业务拒绝时出现
+}
+
+// StopInstanceResult stopInstance 的结果；Stopped=false 表示实例不在运行。
+type StopInstanceResult struct {
+	InstanceID int64 `json:"instanceId"`
+	Stopped    bool  `json:"stopped"`
+}
+
+// writeOK 返回受理成功的响应。
+func writeOK(rw http.ResponseWriter, data any) {
+	writeAsk(rw, http.StatusOK, AskResponse{Success: true, Data: data})
+}
+
+// writeReject 返回业务拒绝：HTTP 200 + success=false，Server 依据 reason 决定是否重试或改派。
+func writeReject(rw http.ResponseWriter, reason RejectReason, msg string, data any) {
+	writeAsk(rw, http.StatusOK, AskResponse{Success: false, Message: string(reason) + ": " + msg, Data: data, Reason: reason})
+}
+
+// writeErr 返回请求本身的错误（请求体非法、记录不存在、存储故障等）。
+func writeErr(rw http.ResponseWriter, code int, err error) {
+	writeAsk(rw, code, AskResponse{Success: false, Message: err.Error()})
+}
+
+func writeAsk(rw http.ResponseWriter, code int, resp AskResponse) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	_ = json.NewEncoder(rw).Encode(resp)
+}
@@ -0,0 +1,105 @@
+package powerjob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// askResp 按具体 data 类型解码 AskResponse。
+type askResp[T any] struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Data    T            `json:"data"`
+	Reason  RejectReason `json:"reason"`
+}
+
+// postAsk 向 Worker 端点发送 JSON 请求并解码响应。
+func postAsk[T any](addr, path string, body any) (askResp[T], int) {
+	var out askResp[T]
+	b, _ := json.Marshal(body)
+	resp, err := http.Post("http://"+addr+path, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return out, 0
+	}
+	defer resp.Body.Close()
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return out, resp.StatusCode
+}
+
+func TestWorker_AskResponse(t *testing.T) {
+	Convey("worker endpoints should answer with an AskResponse envelope", t, func() {
+		release := make(chan struct{})
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("ask.wait", func(ctx context.Context, in string) (string, error) {
+			<-release
+			return "ok", nil
+		}))
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("ask"), WithListenAddr("127.0.0.1:0"),
+			WithClientAPI(&dummyAPI{}), WithMaxConcurrentInstances(1))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+		defer close(release)
+
+		run, code := postAsk[RunJobResult](w.Addr(), "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 101, JobID: 10, ProcessorInfo: "ask.wait"})
+		So(code, ShouldEqual, http.StatusOK)
+		So(run.Success, ShouldBeTrue)
+		So(run.Data.Dispatch, ShouldEqual, DispatchAccepted)
+
+		Convey("unknown processors are rejected", func() {
+			out, code := postAsk[any](w.Addr(), "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 102, ProcessorInfo: "ask.nope"})
+			So(code, ShouldEqual, http.StatusOK)
+			So(out.Success, ShouldBeFalse)
+			So(out.Reason, ShouldEqual, RejectUnknownProcessor)
+			_, err := w.store.Get(ctx, 102)
+			So(err, ShouldEqual, ErrNotFound)
+		})
+
+		Convey("dispatches beyond the concurrency limit are rejected", func() {
+			out, _ := postAsk[any](w.Addr(), "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 103, ProcessorInfo: "ask.wait"})
+			So(out.Success, ShouldBeFalse)
+			So(out.Reason, ShouldEqual, RejectOverloaded)
+		})
+
+		Convey("duplicates carry the existing dispatch state", func() {
+			out, _ := postAsk[RunJobResult](w.Addr(), "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 101, ProcessorInfo: "ask.wait"})
+			So(out.Success, ShouldBeFalse)
+			So(out.Reason, ShouldEqual, RejectDuplicate)
+			So(out.Data.Dispatch, ShouldEqual, DispatchDuplicate)
+		})
+
+		Convey("half-valid bodies are rejected with 400", func() {
+			out, code := postAsk[any](w.Addr(), "/worker/runJob", map[string]any{"jobId": 10})
+			So(code, ShouldEqual, http.StatusBadRequest)
+			So(out.Success, ShouldBeFalse)
+			So(out.Message, ShouldContainSubstring, "instanceId")
+		})
+
+		Convey("stopInstance and queryInstanceStatus report their results", func() {
+			stop, _ := postAsk[StopInstanceResult](w.Addr(), "/worker/stopInstance", map[string]any{"instanceId": 101})
+			So(stop.Success, ShouldBeTrue)
+			So(stop.Data.Stopped, ShouldBeTrue)
+			q, _ := postAsk[InstanceRecord](w.Addr(), "/worker/queryInstanceStatus", map[string]any{"instanceId": 101})
+			So(q.Success, ShouldBeTrue)
+			So(q.Data.Status, ShouldEqual, StateStopped)
+			missing, code := postAsk[any](w.Addr(), "/worker/queryInstanceStatus", map[string]any{"instanceId": 999})
+			So(code, ShouldEqual, http.StatusNotFound)
+			So(missing.Success, ShouldBeFalse)
+		})
+
+		Convey("dispatches after shutdown begins are rejected", func() {
+			w.closing.Store(true)
+			out, _ := postAsk[any](w.Addr(), "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 104, ProcessorInfo: "ask.wait"})
+			So(out.Reason, ShouldEqual, RejectShuttingDown)
+		})
+	})
+}
@@ -0,0 +1,98 @@
+package powerjob
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/logging"
+)
+
+// RetentionPolicy 已结束实例记录的保留策略；字段为 0 表示不按该维度淘汰。
+// 说明：运行中实例以及终态尚未被 Server 确认（Reported=false）的记录永不淘汰。
+type RetentionPolicy struct {
+	MaxAge    time.Duration // 结束时间（UpdatedAt）早于 now-MaxAge 的记录被淘汰
+	MaxCount  int           // 全局最多保留的已结束记录数，超出部分按 UpdatedAt 从旧到新淘汰
+	MaxPerJob int           // 每个 JobID 最多保留的已结束记录数
+	MinAge    time.Duration // 结束时间在 now-MinAge 之后的记录不按 MaxAge 淘汰；MaxCount/MaxPerJob 为硬上限，仍会淘汰其中较旧的记录。Worker 会将其提升到 DedupeWindow
+}
+
+// Pruner 可选扩展：支持删除与按保留策略淘汰记录。
+// 实现该接口的存储会由 Worker 后台清理任务周期性调用 Prune。
+type Pruner interface {
+	// Delete 删除指定实例记录；记录不存在时返回 nil。
+	Delete(ctx context.Context, instanceID int64) error
+	// Prune 按策略淘汰记录，返回删除条数。
+	Prune(ctx context.Context, p RetentionPolicy) (int, error)
+}
+
+// SelectPrunable 从记录集中挑选应被淘汰的实例ID，供各 Storage 实现复用。
+// 参数：recs 全量记录；p 保留策略；now 当前时间。
+// 返回：待删除的 instanceID 列表（无序）。
+func SelectPrunable(recs []InstanceRecord, p RetentionPolicy, now time.Time) []int64 {
+	done := make([]InstanceRecord, 0, len(recs))
+	for _, r := range recs {
+		if IsTerminal(r.Status) && r.Reported {
+			done = append(done, r)
+		}
+	}
+	// 新记录在前，便于按数量截断
+	sort.Slice(done, func(i, j int) bool { return done[i].UpdatedAt.After(done[j].UpdatedAt) })
+	drop := map[int64]struct{}{}
+	perJob := map[int64]int{}
+	kept := 0
+	for _, r := range done {
+		switch {
+		case p.MaxPerJob > 0 && perJob[r.JobID] >= p.MaxPerJob:
+			drop[r.InstanceID] = struct{}{}
+		case p.MaxCount > 0 && kept >= p.MaxCount:
+			drop[r.InstanceID] = struct{}{}
+		case p.MinAge > 0 && now.Sub(r.UpdatedAt) < p.MinAge:
+			perJob[r.JobID]++
+			kept++
+		case p.MaxAge > 0 && now.Sub(r.UpdatedAt) > p.MaxAge:
+			drop[r.InstanceID] = struct{}{}
+		default:
+			perJob[r.JobID]++
+			kept++
+		}
+	}
+	out := make([]int64, 0, len(drop))
+	for id := range drop {
+		out = append(out, id)
+	}
+	return out
+}
+
+// startJanitor 启动后台清理任务：存储实现 Pruner 且周期为正时按 Options.Retention 淘汰记录。
+// 说明：去重窗口内的已结束记录不按 MaxAge 淘汰，否则其重复派发会被再次执行；
+// 但数量超出 MaxCount/MaxPerJob 时仍按从旧到新淘汰，避免存储无限增长，此时被淘汰实例的重复派发按新实例受理。
+func (w *Worker) startJanitor(ctx context.Context) {
+	pr, ok := w.store.(Pruner)
+	if !ok || w.opt.RetentionEvery <= 0 {
+		return
+	}
+	policy := w.opt.Retention
+	if w.opt.DedupeWindow > policy.MinAge {
+		policy.MinAge = w.opt.DedupeWindow
+	}
+	ticker := time.NewTicker(w.opt.RetentionEvery)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := pr.Prune(ctx, policy)
+				if err != nil {
+					logging.L().Warnf(ctx, "prune instance records failed: %v", err)
+					continue
+				}
+				if n > 0 {
+					logging.L().Debugf(ctx, "pruned instance records: count=%d", n)
+				}
+			}
+		}
+	}()
+}
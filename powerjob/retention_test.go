@@ -0,0 +1,86 @@
+package powerjob
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSelectPrunable(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	recs := []InstanceRecord{
+		{InstanceID: 1, JobID: 1, Status: StateSucceed, Reported: true, UpdatedAt: ago(48 * time.Hour)},
+		{InstanceID: 2, JobID: 1, Status: StateFailed, Reported: false, UpdatedAt: ago(48 * time.Hour)}, // 待上报，保留
+		{InstanceID: 3, JobID: 1, Status: StateRunning, UpdatedAt: ago(48 * time.Hour)},                 // 运行中，保留
+		{InstanceID: 4, JobID: 1, Status: StateSucceed, Reported: true, UpdatedAt: ago(3 * time.Minute)},
+		{InstanceID: 5, JobID: 1, Status: StateSucceed, Reported: true, UpdatedAt: ago(2 * time.Minute)},
+		{InstanceID: 6, JobID: 2, Status: StateStopped, Reported: true, UpdatedAt: ago(1 * time.Minute)},
+	}
+	sorted := func(ids []int64) []int64 { sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] }); return ids }
+
+	Convey("records awaiting acknowledgement and running records are never pruned", t, func() {
+		So(sorted(SelectPrunable(recs, RetentionPolicy{MaxAge: time.Hour}, now)), ShouldResemble, []int64{1})
+		So(sorted(SelectPrunable(recs, RetentionPolicy{MaxCount: 0}, now)), ShouldBeEmpty)
+	})
+
+	Convey("MaxPerJob and MaxCount keep the newest records", t, func() {
+		So(sorted(SelectPrunable(recs, RetentionPolicy{MaxPerJob: 1}, now)), ShouldResemble, []int64{1, 4})
+		So(sorted(SelectPrunable(recs, RetentionPolicy{MaxCount: 2}, now)), ShouldResemble, []int64{1, 4})
+	})
+
+	Convey("MinAge protects recent records from MaxAge but not from count caps", t, func() {
+		So(sorted(SelectPrunable(recs, RetentionPolicy{MaxCount: 1, MinAge: 5 * time.Minute}, now)), ShouldResemble, []int64{1, 4, 5})
+		So(sorted(SelectPrunable(recs, RetentionPolicy{MaxAge: time.Minute, MinAge: 5 * time.Minute}, now)), ShouldResemble, []int64{1})
+	})
+}
+
+func TestWorker_Janitor(t *testing.T) {
+	Convey("janitor should prune the default in-memory store periodically", t, func() {
+		w := NewWorker(WithBootstrapServer("x"), WithAppName("demo"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&recordAPI{}),
+			WithRetention(RetentionPolicy{MaxPerJob: 1}, 20*time.Millisecond), WithDedupeWindow(-1))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		for i := int64(1); i <= 3; i++ {
+			_ = w.store.Upsert(ctx, &InstanceRecord{InstanceID: i, JobID: 9, Status: StateSucceed, Reported: i != 1, UpdatedAt: time.Now().Add(time.Duration(i) * time.Second)})
+		}
+		go w.Start(ctx)
+		time.Sleep(100 * time.Millisecond)
+
+		_, err := w.store.Get(ctx, 1) // 未上报，保留
+		So(err, ShouldBeNil)
+		_, err = w.store.Get(ctx, 2)
+		So(err, ShouldNotBeNil)
+		_, err = w.store.Get(ctx, 3)
+		So(err, ShouldBeNil)
+	})
+}
+
+func TestWorker_JanitorKeepsDedupeWindow(t *testing.T) {
+	Convey("records inside the dedupe window survive MaxAge but not the MaxCount hard cap", t, func() {
+		w := NewWorker(WithBootstrapServer("x"), WithAppName("demo"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&recordAPI{}),
+			WithRetention(RetentionPolicy{MaxAge: time.Second, MaxCount: 2}, 20*time.Millisecond), WithDedupeWindow(time.Hour))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		_ = w.store.Upsert(ctx, &InstanceRecord{InstanceID: 1, JobID: 9, Status: StateSucceed, Reported: true, UpdatedAt: time.Now().Add(-2 * time.Hour)})
+		_ = w.store.Upsert(ctx, &InstanceRecord{InstanceID: 2, JobID: 9, Status: StateSucceed, Reported: true, UpdatedAt: time.Now().Add(-2 * time.Minute)})
+		_ = w.store.Upsert(ctx, &InstanceRecord{InstanceID: 3, JobID: 9, Status: StateSucceed, Reported: true, UpdatedAt: time.Now().Add(-time.Minute)})
+		_ = w.store.Upsert(ctx, &InstanceRecord{InstanceID: 4, JobID: 9, Status: StateSucceed, Reported: true, UpdatedAt: time.Now()})
+		go w.Start(ctx)
+		time.Sleep(100 * time.Millisecond)
+
+		// 1 在窗口外且超过 MaxAge；2 虽在窗口内但超出 MaxCount，去重退化为未知
+		for _, id := range []int64{1, 2} {
+			_, err := w.store.Get(ctx, id)
+			So(err, ShouldNotBeNil)
+		}
+		// 3 早于 MaxAge 但在窗口内，仍保留
+		for _, id := range []int64{3, 4} {
+			_, err := w.store.Get(ctx, id)
+			So(err, ShouldBeNil)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package powerjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/logging"
+	"github.com/mengeric/powerjob-client-go/processor"
+)
+
+// LocalLog RunLocal 捕获的一条在线日志。
+type LocalLog struct {
+	Time    time.Time
+	Level   int // 1=DEBUG 2=INFO 3=WARN 4=ERROR
+	Content string
+}
+
+// LocalResult RunLocal 的执行结果。
+type LocalResult struct {
+	InstanceID int64
+	Status     int // StateSucceed / StateFailed / StateStopped
+	Code       int
+	Msg        string
+	Attempts   int // 实际执行次数（含重试）
+	Progress   int // 最后一次上报的进度
+	Logs       []LocalLog
+	Duration   time.Duration
+}
+
+// ErrUnknownProcessor RunLocal 指定的处理器未注册。
+var ErrUnknownProcessor = errors.New("unknown processor")
+
+// localCtxKey 本地执行的日志收集器。
+type localCtxKey struct{}
+
+// localRun 收集一次本地执行的日志与进度，并输出到 out。
+type localRun struct {
+	mu       sync.Mutex
+	out      io.Writer
+	logs     []LocalLog
+	progress int
+}
+
+func (l *localRun) log(level int, content string) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, LocalLog{Time: now, Level: level, Content: content})
+	if l.out != nil {
+		fmt.Fprintf(l.out, "%s [%s] %s\n", now.Format("15:04:05.000"), levelName(level), content)
+	}
+}
+
+func (l *localRun) setProgress(pct int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.progress = pct
+	if l.out != nil {
+		fmt.Fprintf(l.out, "%s [PROGRESS] %d%%\n", time.Now().Format("15:04:05.000"), pct)
+	}
+}
+
+func levelName(level int) string {
+	switch level {
+	case 1:
+		return "DEBUG"
+	case 2:
+		return "INFO"
+	case 3:
+		return "WARN"
+	case 4:
+		return "ERROR"
+	}
+	return fmt.Sprintf("L%d", level)
+}
+
+// localHook 把带本地执行上下文的日志交给对应的收集器。
+func localHook(ctx context.Context, level int, msg string, args ...any) {
+	if l, ok := ctx.Value(localCtxKey{}).(*localRun); ok {
+		l.log(level, hookContent(msg, args...))
+	}
+}
+
+// RunLocal 在本进程内直接执行一个已注册的处理器，不依赖 PowerJob-Server，用于本地调试与端到端测试。
+// 执行路径与 Server 派发一致：同样的中间件链、参数绑定与校验、InstanceTimeout 超时与 TaskRetryNum 重试、
+// processor.SetProgress 进度与 processor.TaskFromContext 实例信息；不写入存储、不产生事件、不上报 Server。
+// 参数：req 至少需要 ProcessorInfo，InstanceID 为 0 时自动生成；out 非 nil 时实时打印在线日志与进度。
+// 返回：执行结果；处理器未注册时返回包装了 ErrUnknownProcessor 的错误（附近似 key 建议）。ctx 取消视为停止。
+func (w *Worker) RunLocal(ctx context.Context, req client.ServerScheduleJobReq, out io.Writer) (LocalResult, error) {
+	if req.InstanceID == 0 {
+		req.InstanceID = time.Now().UnixNano()
+	}
+	if req.ProcessorType == "" {
+		req.ProcessorType = "BUILT_IN"
+	}
+	p, msg := w.lookup(req)
+	if p == nil {
+		return LocalResult{InstanceID: req.InstanceID}, fmt.Errorf("%w: %s", ErrUnknownProcessor, msg)
+	}
+	lr := &localRun{out: out}
+	h := logging.AddHook(localHook)
+	defer h.Remove()
+
+	ctx = context.WithValue(ctx, localCtxKey{}, lr)
+	ctx = processor.WithTask(ctx, taskInfoOf(req))
+	ctx = processor.WithProgress(ctx, lr.setProgress)
+	start := time.Now()
+	o := w.run(ctx, req, p)
+	res := LocalResult{InstanceID: req.InstanceID, Status: o.status, Code: o.code, Msg: o.msg, Attempts: o.attempts, Duration: time.Since(start)}
+	lr.mu.Lock()
+	res.Progress, res.Logs = lr.progress, lr.logs
+	lr.mu.Unlock()
+	if out != nil {
+		fmt.Fprintf(out, "instance %d finished: status=%d code=%d attempts=%d duration=%s msg=%s\n",
+			res.InstanceID, res.Status, res.Code, res.Attempts, res.Duration.Round(time.Millisecond), res.Msg)
+	}
+	return res, nil
+}
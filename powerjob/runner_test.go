@@ -0,0 +1,127 @@
+package powerjob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/logging"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWorker_RunLocal(t *testing.T) {
+	Convey("RunLocal should execute a processor without a server", t, func() {
+		reg := processor.NewRegistry()
+		calls := 0
+		reg.Register(processor.NewFunc("local.ok", func(ctx context.Context, in string) (string, error) {
+			logging.L().Infof(ctx, "got %s", in)
+			processor.SetProgress(ctx, 60)
+			task, _ := processor.TaskFromContext(ctx)
+			return "done " + task.ProcessorKey, nil
+		}))
+		reg.Register(processor.NewFunc("local.flaky", func(ctx context.Context, in string) (string, error) {
+			calls++
+			if calls < 3 {
+				return "", errors.New("boom")
+			}
+			return "ok", nil
+		}))
+		reg.Register(processor.NewFunc("local.slow", func(ctx context.Context, in string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}))
+		panics := 0
+		reg.Register(processor.NewFunc("local.panic", func(ctx context.Context, in string) (string, error) {
+			panics++
+			panic("nil map")
+		}))
+		w := NewWorker(WithRegistry(reg), WithClientAPI(&dummyAPI{}))
+		var out bytes.Buffer
+
+		Convey("success captures logs, progress and result", func() {
+			res, err := w.RunLocal(context.Background(), client.ServerScheduleJobReq{ProcessorInfo: "local.ok", JobParams: "hi"}, &out)
+			So(err, ShouldBeNil)
+			So(res.InstanceID, ShouldNotEqual, 0)
+			So(res.Status, ShouldEqual, StateSucceed)
+			So(res.Msg, ShouldEqual, "done local.ok")
+			So(res.Attempts, ShouldEqual, 1)
+			So(res.Progress, ShouldEqual, 60)
+			So(len(res.Logs), ShouldEqual, 1)
+			So(res.Logs[0].Level, ShouldEqual, 2)
+			So(res.Logs[0].Content, ShouldEqual, "got hi")
+			So(out.String(), ShouldContainSubstring, "[INFO] got hi")
+			So(out.String(), ShouldContainSubstring, "[PROGRESS] 60%")
+			So(out.String(), ShouldContainSubstring, "msg=done local.ok")
+		})
+
+		Convey("failures are retried up to TaskRetryNum", func() {
+			start := time.Now()
+			res, err := w.RunLocal(context.Background(), client.ServerScheduleJobReq{ProcessorInfo: "local.flaky", TaskRetryNum: 1}, nil)
+			So(err, ShouldBeNil)
+			So(res.Status, ShouldEqual, StateFailed)
+			So(res.Attempts, ShouldEqual, 2)
+			So(res.Msg, ShouldContainSubstring, "boom")
+			So(len(res.Logs), ShouldEqual, 1)
+			So(res.Logs[0].Level, ShouldEqual, 3)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, retryBackoff) // 重试前退避等待
+
+			res, _ = w.RunLocal(context.Background(), client.ServerScheduleJobReq{ProcessorInfo: "local.flaky", TaskRetryNum: 1}, nil)
+			So(res.Status, ShouldEqual, StateSucceed)
+			So(calls, ShouldEqual, 3)
+		})
+
+		Convey("recovered panics are not retried", func() {
+			res, err := w.RunLocal(context.Background(), client.ServerScheduleJobReq{ProcessorInfo: "local.panic", TaskRetryNum: 3}, nil)
+			So(err, ShouldBeNil)
+			So(res.Status, ShouldEqual, StateFailed)
+			So(res.Attempts, ShouldEqual, 1)
+			So(panics, ShouldEqual, 1)
+			So(res.Msg, ShouldContainSubstring, "nil map")
+		})
+
+		Convey("InstanceTimeout also bounds the backoff between retries", func() {
+			calls = -100 // 始终失败
+			start := time.Now()
+			res, err := w.RunLocal(context.Background(), client.ServerScheduleJobReq{ProcessorInfo: "local.flaky", InstanceTimeout: 50, TaskRetryNum: 5}, nil)
+			So(err, ShouldBeNil)
+			So(res.Status, ShouldEqual, StateFailed)
+			So(res.Attempts, ShouldEqual, 1)
+			So(res.Msg, ShouldContainSubstring, "instance timeout")
+			So(time.Since(start), ShouldBeLessThan, retryBackoff)
+		})
+
+		Convey("InstanceTimeout fails the run, cancellation stops it", func() {
+			res, err := w.RunLocal(context.Background(), client.ServerScheduleJobReq{ProcessorInfo: "local.slow", InstanceTimeout: 50, TaskRetryNum: 3}, nil)
+			So(err, ShouldBeNil)
+			So(res.Status, ShouldEqual, StateFailed)
+			So(res.Attempts, ShouldEqual, 1)
+			So(res.Msg, ShouldContainSubstring, "instance timeout")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			res, err = w.RunLocal(ctx, client.ServerScheduleJobReq{ProcessorInfo: "local.slow"}, nil)
+			So(err, ShouldBeNil)
+			So(res.Status, ShouldEqual, StateStopped)
+		})
+
+		Convey("unknown processors are rejected with a suggestion", func() {
+			_, err := w.RunLocal(context.Background(), client.ServerScheduleJobReq{ProcessorInfo: "local.okk"}, nil)
+			So(errors.Is(err, ErrUnknownProcessor), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, `did you mean "local.ok"`)
+		})
+	})
+}
+
+func TestLocalHook(t *testing.T) {
+	Convey("local logs should format key-value args like uploaded logs", t, func() {
+		l := &localRun{}
+		ctx := context.WithValue(context.Background(), localCtxKey{}, l)
+		localHook(ctx, 2, "settle", "order", int64(7), "ok", true)
+		So(l.logs[0].Content, ShouldEqual, hookContent("settle", "order", int64(7), "ok", true))
+		So(l.logs[0].Content, ShouldEqual, "settle | order=7 ok=true")
+	})
+}
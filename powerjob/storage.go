@@ -2,9 +2,13 @@ package powerjob
 
 import (
     "context"
+    "errors"
     "time"
 )
 
+// ErrNotFound 实例记录不存在；Storage 实现的 Get/UpdateStatus 应返回（或包装）该错误，并实现 NotFoundReporter 声明这一点。
+var ErrNotFound = errors.New("not found")
+
 // 实例状态常量，保持与多语言文档一致。
 const (
     StateWaitingDispatch      = 1
@@ -27,13 +31,38 @@ type InstanceRecord struct {
     ResultMsg  string
     StartedAt  time.Time
     UpdatedAt  time.Time
+    Reported   bool // 终态是否已成功上报 Server；运行中实例恒为 false
+}
+
+// IsTerminal 判断状态是否为终态（失败/成功/取消/停止）。
+func IsTerminal(status int) bool {
+    switch status {
+    case StateFailed, StateSucceed, StateCanceled, StateStopped:
+        return true
+    }
+    return false
 }
 
 // Storage 为最小持久化接口。
-// 说明：当前版本默认使用内置内存实现；外部可按需实现替换（文档默认不暴露）。
+// 说明：默认使用内置内存实现 MemoryStore；可通过 WithStorage 替换为自定义实现（如 storage/filestore），
+// 自定义实现可用 storage/storagetest 包中的一致性测试套件自检。
 type Storage interface {
     Upsert(ctx context.Context, rec *InstanceRecord) error
     UpdateStatus(ctx context.Context, instanceID int64, status int, resultCode int, resultMsg string) error
     Get(ctx context.Context, instanceID int64) (*InstanceRecord, error)
     ListRunning(ctx context.Context) ([]InstanceRecord, error)
 }
+
+// NotFoundReporter 可选扩展：声明 Get 对不存在的记录总是返回（或包装）ErrNotFound。
+// ReportsNotFound 返回 true 时，Get 的其它错误被视为存储故障，runJob 以 503 拒绝受理以免重复执行；
+// 未实现时沿用旧约定：Get 的任何错误都视为记录不存在，实例照常受理。
+type NotFoundReporter interface {
+    ReportsNotFound() bool
+}
+
+// PendingReportLister 可选扩展：列出已进入终态但尚未成功上报 Server 的实例。
+// 实现该接口的存储会由 Worker 周期性补报终态，上报成功后置 Reported=true 并经 Upsert 写回；
+// 未实现时 Worker 仅上报运行中实例（与旧版本一致）。
+type PendingReportLister interface {
+    ListPendingReport(ctx context.Context) ([]InstanceRecord, error)
+}
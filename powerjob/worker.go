@@ -2,15 +2,22 @@ package powerjob
 
 import (
     "context"
+    "crypto/tls"
     "encoding/json"
+    "errors"
     "fmt"
     "net"
     "net/http"
+    "strings"
     "sync"
+    "sync/atomic"
     "time"
 
+	"github.com/mengeric/powerjob-client-go/auth"
 	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/jobsync"
 	"github.com/mengeric/powerjob-client-go/logging"
+	"github.com/mengeric/powerjob-client-go/metrics"
 	"github.com/mengeric/powerjob-client-go/processor"
 	"github.com/mengeric/powerjob-client-go/scheduler"
 	"github.com/mengeric/powerjob-client-go/tracker"
@@ -18,13 +25,18 @@ import (
 
 // Worker 组件主对象：提供内置 HTTP Server 与后台调度生命周期控制。
 // 说明：Worker 在 Start(ctx) 中自动启动 HTTP Server（监听 Options.ListenAddr），
-// 并开启服务发现、心跳、实例状态与在线日志上报任务。
+// 并开启服务发现、心跳、实例状态与在线日志上报任务；也可通过 Handler 挂载到宿主自己的 HTTP 服务。
 type Worker struct {
     opt   Options
     api   client.ServerAPI
     store Storage
+	reg   *processor.Registry
+	sync  *jobsync.Reconciler
+	mws   []processor.Middleware
+	keyMW map[string][]processor.Middleware
 
 	trk    *tracker.Manager
+	discMu sync.RWMutex // 保护 disc：鉴权白名单会在请求处理中读取
 	disc   *scheduler.Discovery
 	hb     *scheduler.HeartbeatScheduler
 	rep    *scheduler.InstanceReporter
@@ -32,6 +44,17 @@ type Worker struct {
 	srv    *http.Server
 	addrMu sync.RWMutex
 	addr   string
+	hook    logging.HookHandle
+	bus     eventBus
+	slots   chan struct{} // 并发名额，MaxConcurrentInstances<=0 时为 nil
+	closing atomic.Bool   // Start 的 ctx 结束后置位，拒绝新的派发
+	mounted atomic.Bool   // 已通过 Handler 挂载到宿主服务，Start 不再自建监听
+	tls     *tls.Config   // 内置 HTTP 服务的 TLS 配置，nil 表示明文
+	authn   auth.Authenticator
+	auditFn auth.AuditFunc
+	servers serverSet
+	rejects metrics.CounterVec
+	unknown metrics.CounterVec
 }
 
 // NewWorker 创建 Worker。
@@ -49,12 +72,11 @@ func NewWorker(opts ...Option) *Worker {
 		fn(cfg)
 	}
 	cfg.opt.withDefaults()
-    w := &Worker{opt: cfg.opt, trk: tracker.NewManager()}
+    w := &Worker{opt: cfg.opt, trk: tracker.NewManager(), unknown: metrics.CounterVec{MaxLabels: maxUnknownLabels}}
 	if cfg.store != nil {
 		w.store = cfg.store
 	} else {
-		// 避免 import cycle：默认使用包内置的内存实现
-		w.store = newDefaultMemStore()
+		w.store = NewMemoryStore()
 	}
 	if cfg.api != nil {
 		if a, ok := cfg.api.(client.ServerAPI); ok {
@@ -62,7 +84,26 @@ func NewWorker(opts ...Option) *Worker {
 		}
 	}
 	if w.api == nil {
-		w.api = client.NewHTTPServerAPI()
+		// 默认 User-Agent 携带 ClientVersion，用户选项在后可覆盖
+		opts := append([]client.ClientOption{client.WithClientVersion(w.opt.ClientVersion)}, cfg.cliOpts...)
+		w.api = client.NewHTTPServerAPI(opts...)
+	}
+	w.tls = cfg.srvTLS
+	if cfg.allow != nil {
+		cfg.allow.WithDynamic(w.ServerAddresses)
+		w.authn = auth.All(cfg.allow, cfg.authn)
+	} else {
+		w.authn = cfg.authn
+	}
+	w.auditFn = cfg.audit
+	w.reg = cfg.reg
+	w.sync = cfg.sync
+	if w.reg == nil {
+		w.reg = processor.Default
+	}
+	w.mws, w.keyMW = cfg.mws, cfg.keyMW
+	if w.opt.MaxConcurrentInstances > 0 {
+		w.slots = make(chan struct{}, w.opt.MaxConcurrentInstances)
 	}
 	return w
 }
@@ -70,28 +111,22 @@ func NewWorker(opts ...Option) *Worker {
 // Start 启动后台调度（服务发现/心跳/实例上报）。
 // 功能：
 // 1) 先启动内置 HTTP Server 并确定对外地址（可能为随机端口），必要时回填 WorkerAddress；
+//    已通过 Handler 挂载到宿主服务时跳过自建监听，此时 WorkerAddress 必须显式设置；
 // 2) 执行应用断言获取 appId；
-// 3) 启动服务发现、心跳、实例状态与在线日志上报任务；
+// 3) 启动服务发现、心跳、实例状态与在线日志上报任务，以及已结束记录的清理任务；
 // 生命周期：受传入 ctx 控制，ctx.Done 时优雅关闭 HTTP Server 并停止后台协程。
 // 异常：网络失败不抛出，内部日志记录并按周期重试。
 func (w *Worker) Start(ctx context.Context) {
 	// 1) 内置 HTTP Server：先启动监听并确定实际地址
-	mux := http.NewServeMux()
-	w.registerHandlers(mux, "/worker")
-	ln, err := net.Listen("tcp", w.opt.ListenAddr)
-    if err != nil {
-        logging.L().Errorf(ctx, "listen failed: addr=%s err=%v", w.opt.ListenAddr, err)
-        return
-    }
-	w.addrMu.Lock()
-	w.addr = ln.Addr().String()
-	w.addrMu.Unlock()
-	if w.opt.WorkerAddress == "" {
-		w.opt.WorkerAddress = w.addr
+	if w.mounted.Load() {
+		if w.opt.WorkerAddress == "" {
+			logging.L().Errorf(ctx, "worker is mounted via Handler but WorkerAddress is empty")
+			return
+		}
+		go func() { <-ctx.Done(); w.closing.Store(true) }()
+	} else if !w.listen(ctx) {
+		return
 	}
-	w.srv = &http.Server{Addr: w.addr, Handler: mux}
-	go func() { <-ctx.Done(); _ = w.srv.Shutdown(context.Background()) }()
-	go func() { _ = w.srv.Serve(ln) }()
 
 	// 2) App 校验与获取 appId
 	appID, err := w.api.AssertApp(ctx, w.opt.BootstrapServer, w.opt.AppName)
@@ -99,80 +134,327 @@ func (w *Worker) Start(ctx context.Context) {
         logging.L().Warnf(ctx, "assert app failed: %v", err)
     }
 
+	// 2.1) 可选：同步处理器声明的任务定义
+	if w.sync != nil {
+		w.syncJobs(ctx)
+	}
+
 	// 3) Discovery/Heartbeat/Reporter/LogReporter
-	w.disc = scheduler.NewDiscovery(w.api, appID, w.opt.BootstrapServer, w.opt.ClientVersion, int(w.opt.DiscoveryEvery.Seconds()))
+	disc := scheduler.NewDiscovery(w.api, appID, w.opt.BootstrapServer, w.opt.ClientVersion, int(w.opt.DiscoveryEvery.Seconds()))
+	w.discMu.Lock()
+	w.disc = disc
+	w.discMu.Unlock()
 	w.disc.Start(ctx)
 
 	w.hb = scheduler.NewHeartbeat(w.api, w.disc, w.opt.WorkerAddress, int(w.opt.HeartbeatEvery.Seconds()))
 	w.hb.Start(ctx)
 
-    w.rep = scheduler.NewReporter(w.api, w.disc, listerAdapter{Storage: w.store}, w.opt.WorkerAddress, int(w.opt.ReportEvery.Seconds()))
+    w.rep = scheduler.NewReporter(w.api, w.disc, listerAdapter{Storage: w.store, w: w}, w.opt.WorkerAddress, int(w.opt.ReportEvery.Seconds()),
+		scheduler.WithReportKeepAlive(w.opt.ReportKeepAlive), scheduler.WithReportParallelism(w.opt.ReportParallelism))
 	w.rep.Start(ctx)
 
     w.lr = scheduler.NewLogReporter(w.api, w.disc, w.opt.WorkerAddress, int(w.opt.LogReportEvery.Seconds()), w.opt.LogBatchSize)
     w.lr.Start(ctx)
-    // 设置日志上传 Hook：当上下文携带实例ID时，自动将日志通过在线日志通道上报
-    logging.SetHook(w.uploadHook)
+	w.startJanitor(ctx)
+    // 注册日志上传 Hook：仅处理属于本 Worker 的实例上下文，多个 Worker 互不干扰
+    w.hook = logging.AddHook(w.uploadHook)
+	go func() { <-ctx.Done(); w.hook.Remove() }()
 }
 
-// MountHTTP 将组件的 HTTP 路由挂载到宿主 mux，base 前缀默认为 /worker。
+// listen 启动内置 HTTP Server（监听 Options.ListenAddr，配置 WithServerTLS 时为 HTTPS），失败时返回 false。
+func (w *Worker) listen(ctx context.Context) bool {
+	ln, err := net.Listen("tcp", w.opt.ListenAddr)
+	if err != nil {
+		logging.L().Errorf(ctx, "listen failed: addr=%s err=%v", w.opt.ListenAddr, err)
+		return false
+	}
+	if w.tls != nil {
+		ln = tls.NewListener(ln, w.tls)
+	}
+	w.addrMu.Lock()
+	w.addr = ln.Addr().String()
+	w.addrMu.Unlock()
+	if w.opt.WorkerAddress == "" {
+		w.opt.WorkerAddress = w.addr
+	}
+	w.srv = &http.Server{Addr: w.addr, Handler: w.routes("/worker")}
+	go func() { <-ctx.Done(); w.closing.Store(true); _ = w.srv.Shutdown(context.Background()) }()
+	go func() { _ = w.srv.Serve(ln) }()
+	return true
+}
+
+// Handler 返回挂载了组件端点的 http.Handler，供宿主在自己的 http.Server / mux 上提供服务。
+// 功能：调用后 Start 不再自建监听（ListenAddr 被忽略），后台调度照常启动；须在 Start 之前调用。
+// 参数：base 路由前缀，留空默认 "/worker"。PowerJob Server 固定请求 {WorkerAddress}/worker/...，
+// 因此除非宿主自行改写路径，base 应保持默认，并把返回值挂在宿主 mux 的根或 "/worker/" 上。
 // 端点：POST {base}/runJob、{base}/stopInstance、{base}/queryInstanceStatus
-func (w *Worker) registerHandlers(mux *http.ServeMux, base string) {
-	mux.HandleFunc(base+"/runJob", w.handleRunJob)
-	mux.HandleFunc(base+"/stopInstance", w.handleStopInstance)
-	mux.HandleFunc(base+"/queryInstanceStatus", w.handleQueryInstanceStatus)
+// 注意：WorkerAddress 必须通过 WithWorkerAddress 显式设置为宿主服务对 Server 可达的地址。
+func (w *Worker) Handler(base string) http.Handler {
+	if base == "" {
+		base = "/worker"
+	}
+	w.mounted.Store(true)
+	return w.routes(base)
 }
 
-// StartHTTP 创建并启动一个内置的 HTTP Server，监听指定地址并挂载组件路由。
-// 功能：快速把组件跑起来，宿主无需自行创建 http.Server。
-// 参数：
-//   - ctx: 控制生命周期，ctx.Done() 时将优雅关闭；
-//   - addr: 监听地址，支持形如 ":27777"、"127.0.0.1:0"（0 表示随机端口）；
-//   - base: 路由前缀，留空默认 "/worker"。
-//
-// 返回：
-//   - srv: *http.Server 实例，便于观察状态；
-//   - actualAddr: 实际监听地址（当传入 :0 时用于获取随机端口）；
-//   - err: 启动失败时返回错误。
-// 去除对外暴露的 StartHTTP/MountHTTP：组件在 Start(ctx) 内部自动启动 HTTP 服务
+// routes 构造组件路由。
+func (w *Worker) routes(base string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(base+"/runJob", w.guard(w.decompress(w.handleRunJob)))
+	mux.HandleFunc(base+"/stopInstance", w.guard(w.decompress(w.handleStopInstance)))
+	mux.HandleFunc(base+"/queryInstanceStatus", w.guard(w.decompress(w.handleQueryInstanceStatus)))
+	return mux
+}
 
 // handleRunJob 任务执行入口（Server -> Worker）。
+// 响应为 AskResponse：受理时 data 为 RunJobResult；处理器未注册、并发已满、重复派发、Worker 关闭中时
+// success=false 并给出 RejectReason；重复派发的 data 说明该实例正在运行还是已在去重窗口内结束。
 func (w *Worker) handleRunJob(rw http.ResponseWriter, r *http.Request) {
 	var req client.ServerScheduleJobReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErr(rw, http.StatusBadRequest, err)
 		return
 	}
-	if _, ok := w.trk.Get(req.InstanceID); ok {
-		rw.WriteHeader(http.StatusOK)
+	if req.InstanceID <= 0 || req.ProcessorInfo == "" {
+		writeErr(rw, http.StatusBadRequest, errors.New("instanceId and processorInfo are required"))
+		return
+	}
+	if w.closing.Load() {
+		w.reject(rw, RejectShuttingDown, "worker is shutting down", nil)
+		return
+	}
+	p, msg := w.lookup(req)
+	if p == nil {
+		w.unknown.Inc(req.ProcessorInfo)
+		w.reject(rw, RejectUnknownProcessor, msg, nil)
+		return
+	}
+	// 去重判断与登记在同一把实例锁内完成，并发的重复派发只会有一个被受理
+	mu := w.bus.lock(req.InstanceID)
+	mu.Lock()
+	res, err := w.dedupe(r.Context(), req.InstanceID)
+	if err != nil {
+		mu.Unlock()
+		writeErr(rw, http.StatusServiceUnavailable, err)
+		return
+	}
+	if res.Dispatch != DispatchAccepted {
+		mu.Unlock()
+		w.reject(rw, RejectDuplicate, fmt.Sprintf("instance %d is %s", req.InstanceID, strings.ToLower(string(res.Dispatch))), res)
+		return
+	}
+	if !w.acquireSlot() {
+		mu.Unlock()
+		w.reject(rw, RejectOverloaded, fmt.Sprintf("%d instances running", w.opt.MaxConcurrentInstances), nil)
+		return
+	}
+	deliver, err := w.transitionLocked(r.Context(), req.InstanceID, EventStarted, func() error {
+		return w.store.Upsert(r.Context(), &InstanceRecord{InstanceID: req.InstanceID, JobID: req.JobID, Status: StateRunning, StartedAt: time.Now(), UpdatedAt: time.Now()})
+	})
+	if err != nil {
+		// 未能登记运行中记录时不执行：否则重启后无法去重，由 Server 稍后重试
+		w.releaseSlot()
+		mu.Unlock()
+		writeErr(rw, http.StatusServiceUnavailable, err)
 		return
 	}
-	_ = w.store.Upsert(r.Context(), &InstanceRecord{InstanceID: req.InstanceID, JobID: req.JobID, Status: StateRunning, StartedAt: time.Now(), UpdatedAt: time.Now()})
     ins := w.trk.Start(req.InstanceID)
+	mu.Unlock()
+	// STARTED 事件在释放实例锁后异步投递（顺序由 transition 保证），慢订阅者不会阻塞派发响应
+	go deliver()
     // 将实例ID注入上下文，便于日志 Hook 识别并在线上报
-    ins.Ctx = withInstanceID(ins.Ctx, req.InstanceID)
-    go w.execute(r.Context(), req, ins)
-    rw.WriteHeader(http.StatusOK)
+    ins.Ctx = w.withInstanceID(ins.Ctx, req.InstanceID)
+	ins.Ctx = processor.WithTask(ins.Ctx, taskInfoOf(req))
+	ins.Ctx = processor.WithProgress(ins.Ctx, func(pct int) { w.setProgress(req.InstanceID, pct) })
+    go w.execute(req, ins, p)
+	res.Status = StateRunning
+	writeOK(rw, res)
 }
 
-// execute 实例执行与状态更新。
-func (w *Worker) execute(ctx context.Context, req client.ServerScheduleJobReq, ins *tracker.Instance) {
-	p, ok := processor.Get(req.ProcessorInfo)
-	if !ok {
-		_ = w.store.UpdateStatus(context.Background(), req.InstanceID, StateFailed, -1, "processor not found")
-		w.trk.Stop(req.InstanceID)
-		return
+// maxListedKeys 拒绝消息中最多列出的已注册 key 数量。
+const maxListedKeys = 20
+
+// maxUnknownLabels 未注册处理器计数最多区分的 processorInfo 数，其余归入 metrics.OtherLabel。
+const maxUnknownLabels = 100
+
+// lookup 在受理前解析处理器；找不到时返回带近似建议与已注册 key 列表的说明。
+// 说明：Go Worker 仅支持内置处理器，processorType 允许为空或 BUILT_IN。
+func (w *Worker) lookup(req client.ServerScheduleJobReq) (processor.Processor, string) {
+	if t := strings.ToUpper(req.ProcessorType); t != "" && t != "BUILT_IN" {
+		return nil, fmt.Sprintf("processorType %s is not supported by this worker, use BUILT_IN", req.ProcessorType)
 	}
-	// 直接把原始 JSON 字节传给处理器，由处理器自行解码
-	res, err := p.Run(ins.Ctx, []byte(req.JobParams))
-	if err != nil {
-		_ = w.store.UpdateStatus(context.Background(), req.InstanceID, StateFailed, res.Code, err.Error())
-	} else {
-		_ = w.store.UpdateStatus(context.Background(), req.InstanceID, StateSucceed, res.Code, res.Msg)
+	if p, ok := w.reg.Get(req.ProcessorInfo); ok {
+		return p, ""
+	}
+	msg := fmt.Sprintf("processor %q is not registered", req.ProcessorInfo)
+	if s, ok := w.reg.Suggest(req.ProcessorInfo); ok {
+		msg += fmt.Sprintf("; did you mean %q?", s)
+	}
+	keys := w.reg.Keys()
+	if len(keys) > maxListedKeys {
+		keys = append(keys[:maxListedKeys:maxListedKeys], fmt.Sprintf("...(%d more)", len(keys)-maxListedKeys))
 	}
+	return nil, msg + " registered: [" + strings.Join(keys, ", ") + "]"
+}
+
+// execute 实例执行与状态更新；结束后释放并发名额。
+func (w *Worker) execute(req client.ServerScheduleJobReq, ins *tracker.Instance, p processor.Processor) {
+	defer w.releaseSlot()
+	out := w.run(ins.Ctx, req, p)
+	if out.stopped {
+		// Worker 中实例上下文只由 stopInstance（trk.Stop）取消，终态 STOPPED 与对应事件已由其写入；
+		// 处理器此时常把 ctx.Err() 当作错误返回，这里不再覆盖为 FAILED，也不重复发布终态事件
+		return
+	}
+	w.finish(req.InstanceID, out.status, out.code, out.msg)
 	w.trk.Stop(req.InstanceID)
 }
 
+// outcome 一次实例执行的结果。
+type outcome struct {
+	status   int
+	code     int
+	msg      string
+	attempts int
+	stopped  bool // ctx 被取消：Worker 中为 stopInstance，RunLocal 中为调用方取消
+}
+
+// 失败重试的退避：首次等待 retryBackoff，之后逐次翻倍，最长 maxRetryBackoff。
+const (
+	retryBackoff    = 100 * time.Millisecond
+	maxRetryBackoff = 2 * time.Second
+)
+
+// run 执行处理器：InstanceTimeout>0 时整个实例（含重试与退避等待）受该超时约束，超时记为失败且不再重试；
+// 处理器返回错误时按 TaskRetryNum 退避重试，参数解码/校验失败与被 Recover 捕获的 panic 不重试。
+// Worker.execute 与 RunLocal 共用。
+func (w *Worker) run(ctx context.Context, req client.ServerScheduleJobReq, p processor.Processor) outcome {
+	tctx := ctx
+	if req.InstanceTimeout > 0 {
+		var cancel context.CancelFunc
+		tctx, cancel = context.WithTimeout(ctx, time.Duration(req.InstanceTimeout)*time.Millisecond)
+		defer cancel()
+	}
+	// interrupted 在 ctx 取消或实例超时时返回对应结果
+	interrupted := func(code, n int) (outcome, bool) {
+		switch {
+		case ctx.Err() != nil:
+			return outcome{status: StateStopped, msg: "stopped", attempts: n, stopped: true}, true
+		case tctx.Err() != nil:
+			msg := fmt.Sprintf("instance timeout after %s", time.Duration(req.InstanceTimeout)*time.Millisecond)
+			logging.L().Errorf(ctx, "processor %s: %s", req.ProcessorInfo, msg)
+			return outcome{status: StateFailed, code: code, msg: msg, attempts: n}, true
+		}
+		return outcome{}, false
+	}
+	h := w.handlerFor(req.ProcessorInfo, p)
+	wait := retryBackoff
+	for n := 1; ; n++ {
+		// 直接把原始 JSON 字节传给处理器；强类型处理器由适配器按 instanceParams/jobParams 解码
+		res, err := h(tctx, []byte(req.JobParams))
+		if out, ok := interrupted(res.Code, n); ok {
+			return out
+		}
+		if err == nil {
+			return outcome{status: StateSucceed, code: res.Code, msg: res.Msg, attempts: n}
+		}
+		if code, bad := paramsFailure(err); bad {
+			// 参数问题单独标注结果码，并写入在线日志，便于运维在控制台直接修正参数
+			logging.L().Errorf(ctx, "processor %s rejected params: %v", req.ProcessorInfo, err)
+			return outcome{status: StateFailed, code: code, msg: err.Error(), attempts: n}
+		}
+		if n > req.TaskRetryNum || errors.Is(err, processor.ErrPanic) {
+			// panic 多为程序缺陷，重试通常只会重复失败，堆栈已由 Recover 写入日志
+			return outcome{status: StateFailed, code: res.Code, msg: err.Error(), attempts: n}
+		}
+		logging.L().Warnf(ctx, "processor %s attempt %d/%d failed, retrying in %s: %v", req.ProcessorInfo, n, req.TaskRetryNum+1, wait, err)
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-tctx.Done():
+			t.Stop()
+			out, _ := interrupted(res.Code, n)
+			return out
+		}
+		if wait *= 2; wait > maxRetryBackoff {
+			wait = maxRetryBackoff
+		}
+	}
+}
+
+// acquireSlot 占用一个并发名额；未配置 MaxConcurrentInstances 时总是成功。
+func (w *Worker) acquireSlot() bool {
+	if w.slots == nil {
+		return true
+	}
+	select {
+	case w.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// releaseSlot 归还并发名额。
+func (w *Worker) releaseSlot() {
+	if w.slots != nil {
+		<-w.slots
+	}
+}
+
+// finish 写入实例终态并发布对应事件。
+func (w *Worker) finish(instanceID int64, status, code int, msg string) {
+	ctx := context.Background()
+	_ = w.transition(ctx, instanceID, eventTypeOf(status), func() error {
+		return w.store.UpdateStatus(ctx, instanceID, status, code, msg)
+	})
+}
+
+// setProgress 更新实例进度（processor.SetProgress 的回调），进度未变化时不写存储也不发布事件。
+func (w *Worker) setProgress(instanceID int64, pct int) {
+	ctx := context.Background()
+	_ = w.transition(ctx, instanceID, EventProgress, func() error {
+		rec, err := w.store.Get(ctx, instanceID)
+		if err != nil {
+			return err
+		}
+		if rec.Progress == pct || rec.Status != StateRunning {
+			return errUnchanged
+		}
+		rec.Progress, rec.UpdatedAt = pct, time.Now()
+		return w.store.Upsert(ctx, rec)
+	})
+}
+
+// handlerFor 组装处理器调用链：Recover -> 全局中间件 -> key 级中间件 -> Processor.Run。
+func (w *Worker) handlerFor(key string, p processor.Processor) processor.Handler {
+	mws := make([]processor.Middleware, 0, 1+len(w.mws)+len(w.keyMW[key]))
+	mws = append(mws, processor.Recover())
+	mws = append(mws, w.mws...)
+	mws = append(mws, w.keyMW[key]...)
+	return processor.Chain(p.Run, mws...)
+}
+
+// paramsFailure 判断错误是否为参数解码/校验失败，并返回对应结果码。
+func paramsFailure(err error) (int, bool) {
+	switch {
+	case errors.Is(err, processor.ErrBadParams):
+		return processor.CodeBadParams, true
+	case errors.Is(err, processor.ErrInvalidParams):
+		return processor.CodeInvalidParams, true
+	}
+	return 0, false
+}
+
+// taskInfoOf 由派发请求构造处理器可见的实例信息。
+func taskInfoOf(req client.ServerScheduleJobReq) processor.TaskInfo {
+	t := processor.TaskInfo{JobID: req.JobID, InstanceID: req.InstanceID, ProcessorKey: req.ProcessorInfo, JobParams: req.JobParams}
+	if req.InstanceParams != nil {
+		t.InstanceParams = *req.InstanceParams
+	}
+	return t
+}
+
 // Log 推送一条在线日志（供处理器或业务调用）。
 // level: 1=DEBUG, 2=INFO, 3=WARN, 4=ERROR；timeMs：日志时间（毫秒）。
 func (w *Worker) Log(instanceID int64, level int, content string, timeMs int64) {
@@ -194,10 +476,15 @@ func (w *Worker) handleStopInstance(rw http.ResponseWriter, r *http.Request) {
 		writeErr(rw, http.StatusBadRequest, err)
 		return
 	}
-	if w.trk.Stop(body.InstanceID) {
-		_ = w.store.UpdateStatus(r.Context(), body.InstanceID, StateStopped, 0, "stopped")
+	if body.InstanceID <= 0 {
+		writeErr(rw, http.StatusBadRequest, errors.New("instanceId is required"))
+		return
+	}
+	stopped := w.trk.Stop(body.InstanceID)
+	if stopped {
+		w.finish(body.InstanceID, StateStopped, 0, "stopped")
 	}
-	rw.WriteHeader(http.StatusOK)
+	writeOK(rw, StopInstanceResult{InstanceID: body.InstanceID, Stopped: stopped})
 }
 
 // handleQueryInstanceStatus 查询实例状态。
@@ -214,39 +501,62 @@ func (w *Worker) handleQueryInstanceStatus(rw http.ResponseWriter, r *http.Reque
 		writeErr(rw, http.StatusNotFound, err)
 		return
 	}
-	writeJSON(rw, rec)
+	writeOK(rw, rec)
 }
 
-// writeErr/JSON 公共返回工具。
-func writeErr(w http.ResponseWriter, code int, err error) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	_ = json.NewEncoder(w).Encode(map[string]any{"success": false, "message": err.Error()})
-}
-func writeJSON(w http.ResponseWriter, v any) {
-    w.Header().Set("Content-Type", "application/json")
-    _ = json.NewEncoder(w).Encode(v)
+// ListInstances 按条件分页查询本 Worker 的实例记录（任务ID、状态集合、开始/更新时间窗口）。
+// 说明：存储未实现 InstanceQuerier 时仅能查询运行中与待上报的记录；仅作为 Go API 提供，
+// 组件不对外暴露 HTTP 查询端点，需要时由宿主自行包装并负责鉴权与字段裁剪。
+func (w *Worker) ListInstances(ctx context.Context, q InstanceQuery) (InstancePage, error) {
+	return QueryInstances(ctx, w.store, q)
 }
 
-// Addr 返回内置 HTTP Server 的实际监听地址（用于测试或 :0 随机端口场景）。
+// Addr 返回内置 HTTP Server 的实际监听地址（用于测试或 :0 随机端口场景）；通过 Handler 挂载时为空。
 func (w *Worker) Addr() string { w.addrMu.RLock(); defer w.addrMu.RUnlock(); return w.addr }
 
-// storageAdapter 适配调度器对 repo 的依赖（仅用到 ListRunning）。
-type listerAdapter struct{ Storage }
+// listerAdapter 适配调度器对 repo 的依赖：运行中实例 + 待补报终态实例。
+type listerAdapter struct {
+	Storage
+	w *Worker
+}
 
 // ListRunning 将组件存储模型映射为调度器精简视图。
+// 存储实现了 PendingReportLister 时，一并返回尚未上报成功的终态实例。
 func (a listerAdapter) ListRunning(ctx context.Context) ([]scheduler.Running, error) {
     recs, err := a.Storage.ListRunning(ctx)
     if err != nil {
         return nil, err
     }
+	if pl, ok := a.Storage.(PendingReportLister); ok {
+		pending, err := pl.ListPendingReport(ctx)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, pending...)
+	}
 	out := make([]scheduler.Running, 0, len(recs))
 	for _, r := range recs {
-		out = append(out, scheduler.Running{JobID: r.JobID, InstanceID: r.InstanceID, Status: r.Status})
+		it := scheduler.Running{JobID: r.JobID, InstanceID: r.InstanceID, Status: r.Status, Progress: r.Progress}
+		if IsTerminal(r.Status) {
+			it.Terminal, it.Result = true, r.ResultMsg
+		}
+		out = append(out, it)
 	}
 	return out, nil
 }
 
+// AckReported 终态上报成功后置 Reported=true，并发布 EventReported。
+func (a listerAdapter) AckReported(ctx context.Context, instanceID int64) error {
+	return a.w.transition(ctx, instanceID, EventReported, func() error {
+		rec, err := a.Storage.Get(ctx, instanceID)
+		if err != nil {
+			return err
+		}
+		rec.Reported = true
+		return a.Storage.Upsert(ctx, rec)
+	})
+}
+
 // ---- 日志上传 Hook 与实例上下文工具 ----
 
 // ctxKey 用于在 Context 中存放实例ID，避免与外部键冲突。
@@ -254,27 +564,38 @@ type ctxKey string
 
 var ctxKeyIID ctxKey = "powerjob_iid"
 
-// withInstanceID 将实例ID写入 Context。
+// instanceScope 实例上下文：记录实例ID及其所属 Worker，用于 Hook 作用域隔离。
+type instanceScope struct {
+	owner *Worker
+	id    int64
+}
+
+// withInstanceID 将实例ID（及所属 Worker）写入 Context。
 // 参数：ctx 原始上下文；id 实例ID。
 // 返回：包含实例ID的新上下文。
-func withInstanceID(ctx context.Context, id int64) context.Context { return context.WithValue(ctx, ctxKeyIID, id) }
+func (w *Worker) withInstanceID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, ctxKeyIID, instanceScope{owner: w, id: id})
+}
 
-// instanceIDFromContext 尝试从上下文中提取实例ID。
-func instanceIDFromContext(ctx context.Context) (int64, bool) {
-    v := ctx.Value(ctxKeyIID)
-    if v == nil { return 0, false }
-    if id, ok := v.(int64); ok { return id, true }
-    return 0, false
+// scopeFromContext 提取实例上下文。
+func scopeFromContext(ctx context.Context) (instanceScope, bool) {
+	s, ok := ctx.Value(ctxKeyIID).(instanceScope)
+	return s, ok
 }
 
 // uploadHook 将带实例上下文的日志写入在线日志队列。
+// 作用域：仅处理由本 Worker 派发的实例，其它 Worker 的实例日志直接忽略。
 // level：1=DEBUG,2=INFO,3=WARN,4=ERROR。
 // 注意：Hook 不得再次调用 logging.L()，以避免递归。
 func (w *Worker) uploadHook(ctx context.Context, level int, msg string, args ...any) {
     if w.lr == nil { return }
-    iid, ok := instanceIDFromContext(ctx)
-    if !ok || iid == 0 { return }
-    // 组装内容：msg | k=v ...
+    sc, ok := scopeFromContext(ctx)
+    if !ok || sc.owner != w || sc.id == 0 { return }
+    w.Log(sc.id, level, hookContent(msg, args...), 0)
+}
+
+// hookContent 组装日志钩子的内容：msg | k=v ...，在线上报与本地执行共用。
+func hookContent(msg string, args ...any) string {
     content := msg
     // 简单扁平化 key-value
     if len(args) > 0 {
@@ -292,7 +613,7 @@ func (w *Worker) uploadHook(ctx context.Context, level int, msg string, args ...
             }
         }
     }
-    w.Log(iid, level, content, 0)
+    return content
 }
 
 // toString 将任意值转为字符串，避免引入 fmt 分配热点。
@@ -0,0 +1,82 @@
+package powerjob
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWorker_Handler(t *testing.T) {
+	Convey("Handler mode should serve endpoints on the host server without its own listener", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("mount.ok", func(ctx context.Context, in string) (string, error) { return "ok", nil }))
+		host := http.NewServeMux()
+		host.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) { rw.WriteHeader(http.StatusNoContent) })
+		api := &recordAPI{}
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("mount"), WithClientAPI(api),
+			WithIntervals(time.Second, time.Second, 30*time.Second))
+		host.Handle("/worker/", w.Handler(""))
+		ts := httptest.NewServer(host)
+		defer ts.Close()
+		addr := ts.Listener.Addr().String()
+
+		Convey("without WorkerAddress Start refuses to run", func() {
+			w.Start(context.Background())
+			So(w.disc, ShouldBeNil)
+		})
+
+		Convey("with WorkerAddress dispatches and reporting work", func() {
+			w.opt.WorkerAddress = addr
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			w.Start(ctx)
+			So(w.Addr(), ShouldEqual, "")
+			So(w.srv, ShouldBeNil)
+
+			out, code := postAsk[RunJobResult](addr, "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 121, JobID: 12, ProcessorInfo: "mount.ok"})
+			So(code, ShouldEqual, http.StatusOK)
+			So(out.Data.Dispatch, ShouldEqual, DispatchAccepted)
+			resp, err := http.Get(ts.URL + "/healthz")
+			So(err, ShouldBeNil)
+			So(resp.StatusCode, ShouldEqual, http.StatusNoContent)
+			_ = resp.Body.Close()
+
+			time.Sleep(1200 * time.Millisecond)
+			So(api.statuses(121), ShouldResemble, []int{StateSucceed})
+		})
+	})
+}
+
+func TestWorker_ServerTLS(t *testing.T) {
+	Convey("WithServerTLS should serve endpoints over https", t, func() {
+		// 借用 httptest 生成的证书配置
+		certSrv := httptest.NewUnstartedServer(http.NotFoundHandler())
+		certSrv.StartTLS()
+		defer certSrv.Close()
+
+		w := NewWorker(WithBootstrapServer("x"), WithAppName("tls"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI{}),
+			WithServerTLS(certSrv.TLS))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		resp, err := certSrv.Client().Post("https://"+w.Addr()+"/worker/queryInstanceStatus", "application/json", strings.NewReader(`{"instanceId":1}`))
+		So(err, ShouldBeNil)
+		So(resp.StatusCode, ShouldEqual, http.StatusNotFound)
+		_ = resp.Body.Close()
+
+		// 明文请求由 TLS 层直接以 400 拒绝，不会到达端点
+		plain, err := http.Post("http://"+w.Addr()+"/worker/queryInstanceStatus", "application/json", strings.NewReader(`{"instanceId":1}`))
+		So(err, ShouldBeNil)
+		So(plain.StatusCode, ShouldEqual, http.StatusBadRequest)
+		_ = plain.Body.Close()
+	})
+}
@@ -10,9 +10,31 @@ import (
 	"time"
 
 	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// simpleProc 按 sleepMS 休眠的测试处理器，响应取消；注册到 processor.Default 供各 Worker 测试派发。
+type simpleProc struct{}
+
+func (simpleProc) GetTaskKey() string             { return "simple" }
+func (simpleProc) Init(ctx context.Context) error { return nil }
+func (simpleProc) Stop(ctx context.Context) error { return nil }
+func (simpleProc) Run(ctx context.Context, raw []byte) (processor.Result, error) {
+	var in struct {
+		SleepMS int `json:"sleepMS"`
+	}
+	_ = json.Unmarshal(raw, &in)
+	select {
+	case <-time.After(time.Duration(in.SleepMS) * time.Millisecond):
+		return processor.Result{Msg: "ok"}, nil
+	case <-ctx.Done():
+		return processor.Result{Code: processor.CodeInterrupted}, ctx.Err()
+	}
+}
+
+func init() { processor.Register(simpleProc{}) }
+
 // memStore 简易内存实现（加锁），仅用于测试，避免竞态。
 type memStore struct {
 	m  map[int64]*InstanceRecord
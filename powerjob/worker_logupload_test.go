@@ -62,3 +62,27 @@ func TestWorker_LogUpload_Hook(t *testing.T) {
         So(atomic.LoadInt32(&api.count), ShouldBeGreaterThan, 0)
     })
 }
+
+func TestWorker_LogUpload_HookScoping(t *testing.T) {
+    Convey("hooks of multiple workers should only upload their own instances", t, func() {
+        processor.Register(&logProc{})
+        apiA, apiB := &logAPI{}, &logAPI{}
+        newW := func(api *logAPI) *Worker {
+            return NewWorker(WithBootstrapServer("x"), WithAppName("demo"), WithListenAddr("127.0.0.1:0"), WithClientAPI(api), WithLogReporter(1*time.Second, 16))
+        }
+        wa, wb := newW(apiA), newW(apiB)
+        ctx, cancel := context.WithCancel(context.Background())
+        defer cancel()
+        go wa.Start(ctx)
+        go wb.Start(ctx)
+        time.Sleep(60 * time.Millisecond)
+
+        req := client.ServerScheduleJobReq{InstanceID: 56, JobID: 1, ProcessorInfo: "logproc", JobParams: `{}`}
+        b, _ := json.Marshal(req)
+        _, _ = http.Post("http://"+wa.Addr()+"/worker/runJob", "application/json", bytes.NewReader(b))
+
+        time.Sleep(1200 * time.Millisecond)
+        So(atomic.LoadInt32(&apiA.count), ShouldBeGreaterThan, 0)
+        So(atomic.LoadInt32(&apiB.count), ShouldEqual, 0)
+    })
+}
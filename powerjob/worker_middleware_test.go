@@ -0,0 +1,63 @@
+package powerjob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWorker_Middleware(t *testing.T) {
+	Convey("worker should invoke processors through global and per-key middlewares", t, func() {
+		var mu sync.Mutex
+		var trace []string
+		record := func(name string) processor.Middleware {
+			return func(next processor.Handler) processor.Handler {
+				return func(ctx context.Context, raw []byte) (processor.Result, error) {
+					task, _ := processor.TaskFromContext(ctx)
+					mu.Lock()
+					trace = append(trace, name+":"+task.ProcessorKey)
+					mu.Unlock()
+					return next(ctx, raw)
+				}
+			}
+		}
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("mw.ok", func(ctx context.Context, in string) (string, error) { return "ok", nil }))
+		reg.Register(processor.NewFunc("mw.panic", func(ctx context.Context, in string) (string, error) { panic("boom") }))
+		w := NewWorker(
+			WithRegistry(reg), WithBootstrapServer("x"), WithAppName("mw"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&logAPI{}),
+			WithMiddleware(record("global")),
+			WithProcessorMiddleware("mw.ok", record("key")),
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		for i, key := range []string{"mw.ok", "mw.panic"} {
+			b, _ := json.Marshal(client.ServerScheduleJobReq{InstanceID: int64(61 + i), JobID: 6, ProcessorInfo: key})
+			resp, err := http.Post("http://"+w.Addr()+"/worker/runJob", "application/json", bytes.NewReader(b))
+			So(err, ShouldBeNil)
+			_ = resp.Body.Close()
+			time.Sleep(30 * time.Millisecond)
+		}
+
+		mu.Lock()
+		So(trace, ShouldResemble, []string{"global:mw.ok", "key:mw.ok", "global:mw.panic"})
+		mu.Unlock()
+		ok, _ := w.store.Get(ctx, 61)
+		So(ok.Status, ShouldEqual, StateSucceed)
+		// panic 被捕获并记为失败，进程不受影响
+		bad, _ := w.store.Get(ctx, 62)
+		So(bad.Status, ShouldEqual, StateFailed)
+		So(bad.ResultMsg, ShouldContainSubstring, "processor panic: boom")
+	})
+}
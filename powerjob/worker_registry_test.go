@@ -0,0 +1,155 @@
+package powerjob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// tagProc 返回固定 Msg，用于区分不同注册表中的同名处理器。
+type tagProc struct{ tag string }
+
+func (p *tagProc) GetTaskKey() string             { return "shared.key" }
+func (p *tagProc) Init(ctx context.Context) error { return nil }
+func (p *tagProc) Stop(ctx context.Context) error { return nil }
+func (p *tagProc) Run(ctx context.Context, raw []byte) (processor.Result, error) {
+	return processor.Result{Code: 0, Msg: p.tag}, nil
+}
+
+func TestWorker_Registry(t *testing.T) {
+	Convey("workers with own registries should resolve the same key independently", t, func() {
+		ra, rb := processor.NewRegistry(), processor.NewRegistry()
+		ra.Register(&tagProc{tag: "app-a"})
+		rb.Register(&tagProc{tag: "app-b"})
+		wa := NewWorker(WithRegistry(ra), WithBootstrapServer("x"), WithAppName("a"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI{}))
+		wb := NewWorker(WithRegistry(rb), WithBootstrapServer("x"), WithAppName("b"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI{}))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go wa.Start(ctx)
+		go wb.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		for _, w := range []*Worker{wa, wb} {
+			b, _ := json.Marshal(client.ServerScheduleJobReq{InstanceID: 31, JobID: 3, ProcessorInfo: "shared.key"})
+			resp, err := http.Post("http://"+w.Addr()+"/worker/runJob", "application/json", bytes.NewReader(b))
+			So(err, ShouldBeNil)
+			_ = resp.Body.Close()
+		}
+		time.Sleep(40 * time.Millisecond)
+
+		ga, err := wa.store.Get(ctx, 31)
+		So(err, ShouldBeNil)
+		So(ga.ResultMsg, ShouldEqual, "app-a")
+		gb, err := wb.store.Get(ctx, 31)
+		So(err, ShouldBeNil)
+		So(gb.ResultMsg, ShouldEqual, "app-b")
+	})
+}
+
+func TestWorker_TypedProcessor(t *testing.T) {
+	Convey("worker should bind params for typed processors and flag decode failures", t, func() {
+		type in struct {
+			N int `json:"n"`
+		}
+		type out struct {
+			Double int `json:"double"`
+		}
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("typed.double", func(ctx context.Context, p in) (out, error) { return out{Double: p.N * 2}, nil }))
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("typed"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&logAPI{}))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		insParams := `{"n":21}`
+		for _, req := range []client.ServerScheduleJobReq{
+			{InstanceID: 41, JobID: 4, ProcessorInfo: "typed.double", JobParams: `{"n":1}`, InstanceParams: &insParams},
+			{InstanceID: 42, JobID: 4, ProcessorInfo: "typed.double", JobParams: `{"n":"x"}`},
+		} {
+			b, _ := json.Marshal(req)
+			resp, err := http.Post("http://"+w.Addr()+"/worker/runJob", "application/json", bytes.NewReader(b))
+			So(err, ShouldBeNil)
+			_ = resp.Body.Close()
+		}
+		time.Sleep(40 * time.Millisecond)
+
+		ok, _ := w.store.Get(ctx, 41)
+		So(ok.Status, ShouldEqual, StateSucceed)
+		So(ok.ResultMsg, ShouldEqual, `{"double":42}`)
+		bad, _ := w.store.Get(ctx, 42)
+		So(bad.Status, ShouldEqual, StateFailed)
+		So(bad.ResultCode, ShouldEqual, processor.CodeBadParams)
+	})
+}
+
+func TestWorker_InvalidParams(t *testing.T) {
+	Convey("validation failures should fail the instance with CodeInvalidParams", t, func() {
+		type in struct {
+			Name string `json:"name" validate:"required"`
+		}
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("typed.hello", func(ctx context.Context, p in) (string, error) { return "hi " + p.Name, nil }))
+		api := &logAPI{}
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("typed"), WithListenAddr("127.0.0.1:0"), WithClientAPI(api), WithLogReporter(1*time.Second, 16))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		b, _ := json.Marshal(client.ServerScheduleJobReq{InstanceID: 43, JobID: 4, ProcessorInfo: "typed.hello", JobParams: `{}`})
+		resp, err := http.Post("http://"+w.Addr()+"/worker/runJob", "application/json", bytes.NewReader(b))
+		So(err, ShouldBeNil)
+		_ = resp.Body.Close()
+		time.Sleep(40 * time.Millisecond)
+
+		rec, _ := w.store.Get(ctx, 43)
+		So(rec.Status, ShouldEqual, StateFailed)
+		So(rec.ResultCode, ShouldEqual, processor.CodeInvalidParams)
+		So(rec.ResultMsg, ShouldContainSubstring, "name: is required")
+
+		// 校验信息进入在线日志
+		time.Sleep(1100 * time.Millisecond)
+		So(atomic.LoadInt32(&api.count), ShouldBeGreaterThan, 0)
+	})
+}
+
+func TestWorker_UnknownProcessor(t *testing.T) {
+	Convey("unknown processors should be rejected up front with suggestions and counted", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("order.settle", func(ctx context.Context, in string) (string, error) { return "ok", nil }))
+		reg.Register(processor.NewFunc("order.refund", func(ctx context.Context, in string) (string, error) { return "ok", nil }))
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("unk"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI{}))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		out, _ := postAsk[any](w.Addr(), "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 111, ProcessorInfo: "order.setle"})
+		So(out.Success, ShouldBeFalse)
+		So(out.Reason, ShouldEqual, RejectUnknownProcessor)
+		So(out.Message, ShouldContainSubstring, `did you mean "order.settle"?`)
+		So(out.Message, ShouldContainSubstring, "registered: [order.refund, order.settle]")
+		_, err := w.store.Get(ctx, 111)
+		So(err, ShouldEqual, ErrNotFound)
+
+		out, _ = postAsk[any](w.Addr(), "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 112, ProcessorType: "SHELL", ProcessorInfo: "order.settle"})
+		So(out.Reason, ShouldEqual, RejectUnknownProcessor)
+		So(out.Message, ShouldContainSubstring, "processorType SHELL")
+
+		ok, _ := postAsk[RunJobResult](w.Addr(), "/worker/runJob", client.ServerScheduleJobReq{InstanceID: 113, ProcessorType: "BUILT_IN", ProcessorInfo: "order.settle"})
+		So(ok.Success, ShouldBeTrue)
+
+		m := w.Metrics()
+		So(m.Rejected[RejectUnknownProcessor], ShouldEqual, 2)
+		So(m.UnknownProcessors["order.setle"], ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,75 @@
+package powerjob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordAPI 记录状态上报请求的 ServerAPI 桩。
+type recordAPI struct {
+	mu      sync.Mutex
+	reports []client.TaskTrackerReportInstanceStatusReq
+}
+
+func (a *recordAPI) AssertApp(ctx context.Context, host, app string) (int64, error) { return 1, nil }
+func (a *recordAPI) Acquire(ctx context.Context, base string, appID int64, cur, ver string) (string, error) {
+	return base, nil
+}
+func (a *recordAPI) Heartbeat(ctx context.Context, addr string, hb client.WorkerHeartbeat) error {
+	return nil
+}
+func (a *recordAPI) ReportLog(ctx context.Context, addr string, req client.WorkerLogReportReq) error {
+	return nil
+}
+func (a *recordAPI) ReportInstanceStatus(ctx context.Context, addr string, req client.TaskTrackerReportInstanceStatusReq) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reports = append(a.reports, req)
+	return nil
+}
+
+// statuses 返回某实例按时间顺序上报过的状态。
+func (a *recordAPI) statuses(iid int64) []int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var out []int
+	for _, r := range a.reports {
+		if r.InstanceID == iid {
+			out = append(out, r.InstanceStatus)
+		}
+	}
+	return out
+}
+
+func TestWorker_TerminalReport(t *testing.T) {
+	Convey("terminal status should be reported once and then acknowledged", t, func() {
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("report.ok", func(ctx context.Context, in string) (string, error) { return "done", nil }))
+		api := &recordAPI{}
+		w := NewWorker(WithRegistry(reg), WithBootstrapServer("x"), WithAppName("rep"), WithListenAddr("127.0.0.1:0"), WithClientAPI(api),
+			WithIntervals(time.Second, time.Second, 30*time.Second))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go w.Start(ctx)
+		time.Sleep(50 * time.Millisecond)
+
+		b, _ := json.Marshal(client.ServerScheduleJobReq{InstanceID: 71, JobID: 7, ProcessorInfo: "report.ok"})
+		resp, err := http.Post("http://"+w.Addr()+"/worker/runJob", "application/json", bytes.NewReader(b))
+		So(err, ShouldBeNil)
+		_ = resp.Body.Close()
+
+		time.Sleep(2200 * time.Millisecond)
+		So(api.statuses(71), ShouldResemble, []int{StateSucceed})
+		rec, _ := w.store.Get(ctx, 71)
+		So(rec.Reported, ShouldBeTrue)
+	})
+}
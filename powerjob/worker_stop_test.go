@@ -66,7 +66,7 @@ func (d *dummyAPI2) AssertApp(ctx context.Context, host, app string) (int64, err
 
 func TestWorker_StopInstance(t *testing.T) {
 	Convey("stopInstance should cancel running job", t, func() {
-		w := NewWorker(withStore(&memStore2{}), WithBootstrapServer("x"), WithAppName("demo"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI2{}))
+		w := NewWorker(WithStorage(&memStore2{}), WithBootstrapServer("x"), WithAppName("demo"), WithListenAddr("127.0.0.1:0"), WithClientAPI(&dummyAPI2{}))
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 		go w.Start(ctx)
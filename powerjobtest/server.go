@@ -0,0 +1,390 @@
+// Package powerjobtest 提供进程内的 PowerJob-Server 替身，用于集成测试：
+// 实现 Worker 依赖的 assert/acquire/心跳/状态上报/日志上报接口并记录收到的全部请求，
+// 可向被测 Worker 派发 runJob/stopInstance，支持延迟、HTTP 错误与 success=false 故障注入，
+// 并提供"等待实例上报某状态"等断言辅助。
+package powerjobtest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+)
+
+// Endpoint Server 端点路径。
+type Endpoint string
+
+const (
+	EndpointAssert       Endpoint = "/server/assert"
+	EndpointAcquire      Endpoint = "/server/acquire"
+	EndpointHeartbeat    Endpoint = "/server/workerHeartbeat"
+	EndpointReportStatus Endpoint = "/server/reportInstanceStatus"
+	EndpointReportLog    Endpoint = "/server/reportLog"
+)
+
+// 实例状态（与 PowerJob 一致）。
+const (
+	StatusRunning  = 3
+	StatusFailed   = 4
+	StatusSucceed  = 5
+	StatusCanceled = 9
+	StatusStopped  = 10
+)
+
+// Fault 注入到某个端点的故障：先等待 Latency，再按 Status（非 0 时返回该 HTTP 状态码）
+// 或 Fail（非空时返回 success=false 与该消息）响应。Times>0 时只生效该次数。
+type Fault struct {
+	Latency time.Duration
+	Status  int
+	Fail    string
+	Times   int
+}
+
+// Server 进程内 PowerJob-Server 替身，并发安全。
+type Server struct {
+	ts         *httptest.Server
+	appName    string
+	appID      int64
+	workerPath string
+	hc         *http.Client
+	hook       func(r *http.Request, body []byte)
+
+	mu         sync.Mutex
+	faults     map[Endpoint]*Fault
+	requests   map[Endpoint]int
+	heartbeats []client.WorkerHeartbeat
+	statuses   []client.TaskTrackerReportInstanceStatusReq
+	logs       []client.InstanceLogContent
+	changed    chan struct{} // 每次记录新数据时关闭并替换，用于等待
+}
+
+// Option New 的可选项。
+type Option func(*Server)
+
+// WithApp 指定注册的应用名与应用ID；默认接受任意应用名，应用ID为 1。
+func WithApp(name string, id int64) Option { return func(s *Server) { s.appName, s.appID = name, id } }
+
+// WithWorkerPath 被测 Worker 端点的前缀，默认 "/worker"（与 Worker.Handler 默认值一致）。
+func WithWorkerPath(base string) Option { return func(s *Server) { s.workerPath = base } }
+
+// WithHTTPClient 向 Worker 派发 runJob/stopInstance 所用的 HTTP 客户端，默认 http.DefaultClient；
+// 被测 Worker 启用 TLS/mTLS 时传入配置好证书的客户端。
+func WithHTTPClient(hc *http.Client) Option { return func(s *Server) { s.hc = hc } }
+
+// WithRequestHook 在向 Worker 发送请求前调用，可用于添加鉴权头或签名，
+// 例如 func(r *http.Request, b []byte) { auth.Sign(r, secret, b) }。
+func WithRequestHook(fn func(r *http.Request, body []byte)) Option {
+	return func(s *Server) { s.hook = fn }
+}
+
+// New 启动 Server，tb 结束时自动关闭。
+func New(tb testing.TB, opts ...Option) *Server {
+	s := &Server{appID: 1, workerPath: "/worker", hc: http.DefaultClient, faults: map[Endpoint]*Fault{}, requests: map[Endpoint]int{}, changed: make(chan struct{})}
+	for _, fn := range opts {
+		fn(s)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(string(EndpointAssert), s.handle(EndpointAssert, s.assert))
+	mux.HandleFunc(string(EndpointAcquire), s.handle(EndpointAcquire, s.acquire))
+	mux.HandleFunc(string(EndpointHeartbeat), s.handle(EndpointHeartbeat, s.heartbeat))
+	mux.HandleFunc(string(EndpointReportStatus), s.handle(EndpointReportStatus, s.reportStatus))
+	mux.HandleFunc(string(EndpointReportLog), s.handle(EndpointReportLog, s.reportLog))
+	s.ts = httptest.NewServer(mux)
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Addr 返回 host:port，作为 Worker 的 BootstrapServer。
+func (s *Server) Addr() string { return s.ts.Listener.Addr().String() }
+
+// URL 返回 http://host:port。
+func (s *Server) URL() string { return s.ts.URL }
+
+// Close 关闭 Server。
+func (s *Server) Close() { s.ts.Close() }
+
+// Inject 为端点设置故障，覆盖之前的设置。
+func (s *Server) Inject(e Endpoint, f Fault) {
+	s.mu.Lock()
+	s.faults[e] = &f
+	s.mu.Unlock()
+}
+
+// Clear 清除端点的故障。
+func (s *Server) Clear(e Endpoint) {
+	s.mu.Lock()
+	delete(s.faults, e)
+	s.mu.Unlock()
+}
+
+// Requests 返回端点收到的请求数（含被注入故障的请求）。
+func (s *Server) Requests(e Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[e]
+}
+
+// Heartbeats 返回收到的全部心跳。
+func (s *Server) Heartbeats() []client.WorkerHeartbeat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]client.WorkerHeartbeat(nil), s.heartbeats...)
+}
+
+// StatusReports 返回收到的全部状态上报。
+func (s *Server) StatusReports() []client.TaskTrackerReportInstanceStatusReq {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]client.TaskTrackerReportInstanceStatusReq(nil), s.statuses...)
+}
+
+// Statuses 返回某实例按到达顺序上报过的状态。
+func (s *Server) Statuses(instanceID int64) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []int
+	for _, r := range s.statuses {
+		if r.InstanceID == instanceID {
+			out = append(out, r.InstanceStatus)
+		}
+	}
+	return out
+}
+
+// Logs 返回某实例上报的在线日志内容；instanceID 为 0 时返回全部。
+func (s *Server) Logs(instanceID int64) []client.InstanceLogContent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []client.InstanceLogContent
+	for _, l := range s.logs {
+		if instanceID == 0 || l.InstanceID == instanceID {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// WorkerAddress 返回最近一次心跳中的 Worker 地址，尚未收到心跳时为空。
+func (s *Server) WorkerAddress() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.heartbeats) == 0 {
+		return ""
+	}
+	return s.heartbeats[len(s.heartbeats)-1].WorkerAddress
+}
+
+// handle 统计请求、解压请求体并应用故障注入。
+func (s *Server) handle(e Endpoint, h func(r *http.Request, body []byte) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[e]++
+		var f Fault
+		if p := s.faults[e]; p != nil {
+			f = *p
+			if p.Times > 0 {
+				if p.Times--; p.Times == 0 {
+					delete(s.faults, e)
+				}
+			}
+		}
+		s.mu.Unlock()
+		if f.Latency > 0 {
+			select {
+			case <-time.After(f.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if f.Status != 0 {
+			http.Error(w, http.StatusText(f.Status), f.Status)
+			return
+		}
+		if f.Fail != "" {
+			writeResp(w, client.CommonResp[any]{Success: false, Message: f.Fail})
+			return
+		}
+		body, err := readBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, err := h(r, body)
+		if err != nil {
+			writeResp(w, client.CommonResp[any]{Success: false, Message: err.Error()})
+			return
+		}
+		writeResp(w, client.CommonResp[any]{Success: true, Data: data})
+	}
+}
+
+func (s *Server) assert(r *http.Request, _ []byte) (any, error) {
+	if name := r.URL.Query().Get("appName"); s.appName != "" && name != s.appName {
+		return nil, fmt.Errorf("app %q is not registered", name)
+	}
+	return s.appID, nil
+}
+
+func (s *Server) acquire(*http.Request, []byte) (any, error) { return s.Addr(), nil }
+
+func (s *Server) heartbeat(_ *http.Request, body []byte) (any, error) {
+	var hb client.WorkerHeartbeat
+	if err := json.Unmarshal(body, &hb); err != nil {
+		return nil, err
+	}
+	s.record(func() { s.heartbeats = append(s.heartbeats, hb) })
+	return nil, nil
+}
+
+func (s *Server) reportStatus(_ *http.Request, body []byte) (any, error) {
+	var req client.TaskTrackerReportInstanceStatusReq
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	s.record(func() { s.statuses = append(s.statuses, req) })
+	return nil, nil
+}
+
+func (s *Server) reportLog(_ *http.Request, body []byte) (any, error) {
+	var req client.WorkerLogReportReq
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	s.record(func() { s.logs = append(s.logs, req.InstanceLogContents...) })
+	return nil, nil
+}
+
+// record 在锁内写入数据并唤醒等待者。
+func (s *Server) record(fn func()) {
+	s.mu.Lock()
+	fn()
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mu.Unlock()
+}
+
+// readBody 读取请求体，支持 gzip 压缩的请求。
+func readBody(r *http.Request) ([]byte, error) {
+	var rd io.Reader = r.Body
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		rd = zr
+	}
+	return io.ReadAll(rd)
+}
+
+func writeResp(w http.ResponseWriter, resp client.CommonResp[any]) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Wait 等待 cond 成立（每次收到新数据时重新判断），超时返回错误。
+func (s *Server) Wait(timeout time.Duration, cond func(s *Server) bool) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		s.mu.Lock()
+		ch := s.changed
+		s.mu.Unlock()
+		if cond(s) {
+			return nil
+		}
+		select {
+		case <-ch:
+		case <-deadline.C:
+			return fmt.Errorf("powerjobtest: condition not met within %s", timeout)
+		}
+	}
+}
+
+// WaitStatus 等待实例上报 status，超时返回错误（附已上报的状态序列）。
+func (s *Server) WaitStatus(instanceID int64, status int, timeout time.Duration) error {
+	err := s.Wait(timeout, func(s *Server) bool {
+		for _, st := range s.Statuses(instanceID) {
+			if st == status {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return fmt.Errorf("powerjobtest: instance %d not reported with status %d within %s, got %v", instanceID, status, timeout, s.Statuses(instanceID))
+	}
+	return nil
+}
+
+// WaitSucceeded 等待实例上报成功。
+func (s *Server) WaitSucceeded(instanceID int64, timeout time.Duration) error {
+	return s.WaitStatus(instanceID, StatusSucceed, timeout)
+}
+
+// WaitHeartbeat 等待收到至少一次心跳，返回 Worker 地址。
+func (s *Server) WaitHeartbeat(timeout time.Duration) (string, error) {
+	err := s.Wait(timeout, func(s *Server) bool { return s.WorkerAddress() != "" })
+	return s.WorkerAddress(), err
+}
+
+// AskResponse Worker 端点的响应。
+type AskResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Reason  string          `json:"reason"`
+}
+
+// RunJob 向 Worker 派发实例；workerAddr 为空时使用最近一次心跳中的地址。
+func (s *Server) RunJob(ctx context.Context, workerAddr string, req client.ServerScheduleJobReq) (AskResponse, error) {
+	return s.ask(ctx, workerAddr, "/runJob", req)
+}
+
+// StopInstance 通知 Worker 停止实例；workerAddr 为空时使用最近一次心跳中的地址。
+func (s *Server) StopInstance(ctx context.Context, workerAddr string, instanceID int64) (AskResponse, error) {
+	return s.ask(ctx, workerAddr, "/stopInstance", map[string]int64{"instanceId": instanceID})
+}
+
+func (s *Server) ask(ctx context.Context, addr, path string, body any) (AskResponse, error) {
+	var out AskResponse
+	if addr == "" {
+		if addr = s.WorkerAddress(); addr == "" {
+			return out, fmt.Errorf("powerjobtest: no worker address, wait for a heartbeat or pass it explicitly")
+		}
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return out, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, addr+s.workerPath+path, bytes.NewReader(b))
+	if err != nil {
+		return out, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.hook != nil {
+		s.hook(req, b)
+	}
+	res, err := s.hc.Do(req)
+	if err != nil {
+		return out, err
+	}
+	defer res.Body.Close()
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return out, fmt.Errorf("powerjobtest: %s => %d: %w", path, res.StatusCode, err)
+	}
+	return out, nil
+}
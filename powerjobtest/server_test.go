@@ -0,0 +1,117 @@
+package powerjobtest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/auth"
+	"github.com/mengeric/powerjob-client-go/client"
+	"github.com/mengeric/powerjob-client-go/logging"
+	"github.com/mengeric/powerjob-client-go/powerjob"
+	"github.com/mengeric/powerjob-client-go/powerjobtest"
+	"github.com/mengeric/powerjob-client-go/processor"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestServer_WorkerFlow(t *testing.T) {
+	Convey("a worker should run dispatched instances end to end against the fake server", t, func() {
+		srv := powerjobtest.New(t, powerjobtest.WithApp("it-app", 42))
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("it.echo", func(ctx context.Context, in string) (string, error) {
+			logging.L().Infof(ctx, "echo %s", in)
+			return "echo:" + in, nil
+		}))
+		reg.Register(processor.NewFunc("it.block", func(ctx context.Context, in string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}))
+		w := powerjob.NewWorker(powerjob.WithRegistry(reg), powerjob.WithBootstrapServer(srv.Addr()), powerjob.WithAppName("it-app"),
+			powerjob.WithListenAddr("127.0.0.1:0"), powerjob.WithIntervals(time.Second, time.Second, time.Second),
+			powerjob.WithLogReporter(time.Second, 16))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w.Start(ctx)
+
+		addr, err := srv.WaitHeartbeat(3 * time.Second)
+		So(err, ShouldBeNil)
+		So(addr, ShouldEqual, w.Addr())
+
+		res, err := srv.RunJob(ctx, "", client.ServerScheduleJobReq{InstanceID: 7001, JobID: 70, ProcessorInfo: "it.echo", JobParams: "hi"})
+		So(err, ShouldBeNil)
+		So(res.Success, ShouldBeTrue)
+		So(srv.WaitSucceeded(7001, 5*time.Second), ShouldBeNil)
+		So(srv.Wait(3*time.Second, func(s *powerjobtest.Server) bool { return len(s.Logs(7001)) > 0 }), ShouldBeNil)
+		So(srv.Logs(7001)[0].LogContent, ShouldContainSubstring, "echo hi")
+
+		res, _ = srv.RunJob(ctx, "", client.ServerScheduleJobReq{InstanceID: 7002, JobID: 70, ProcessorInfo: "it.block"})
+		So(res.Success, ShouldBeTrue)
+		res, err = srv.StopInstance(ctx, "", 7002)
+		So(err, ShouldBeNil)
+		var stop powerjob.StopInstanceResult
+		_ = json.Unmarshal(res.Data, &stop)
+		So(stop.Stopped, ShouldBeTrue)
+		So(srv.WaitStatus(7002, powerjobtest.StatusStopped, 5*time.Second), ShouldBeNil)
+
+		res, _ = srv.RunJob(ctx, "", client.ServerScheduleJobReq{InstanceID: 7003, JobID: 70, ProcessorInfo: "it.missing"})
+		So(res.Success, ShouldBeFalse)
+		So(res.Reason, ShouldEqual, string(powerjob.RejectUnknownProcessor))
+	})
+}
+
+func TestServer_RequestHook(t *testing.T) {
+	Convey("dispatches should pass a worker protected by HMAC when signed in the request hook", t, func() {
+		secret := []byte("it-secret")
+		srv := powerjobtest.New(t, powerjobtest.WithApp("auth-app", 3), powerjobtest.WithHTTPClient(&http.Client{Timeout: 3 * time.Second}),
+			powerjobtest.WithRequestHook(func(r *http.Request, b []byte) { auth.Sign(r, secret, b) }))
+		reg := processor.NewRegistry()
+		reg.Register(processor.NewFunc("it.echo", func(ctx context.Context, in string) (string, error) { return in, nil }))
+		w := powerjob.NewWorker(powerjob.WithRegistry(reg), powerjob.WithBootstrapServer(srv.Addr()), powerjob.WithAppName("auth-app"),
+			powerjob.WithListenAddr("127.0.0.1:0"), powerjob.WithIntervals(time.Second, time.Second, time.Second),
+			powerjob.WithAuthenticator(auth.HMAC(time.Minute, secret)))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w.Start(ctx)
+		_, err := srv.WaitHeartbeat(3 * time.Second)
+		So(err, ShouldBeNil)
+
+		res, err := srv.RunJob(ctx, "", client.ServerScheduleJobReq{InstanceID: 7101, JobID: 71, ProcessorInfo: "it.echo", JobParams: "hi"})
+		So(err, ShouldBeNil)
+		So(res.Success, ShouldBeTrue)
+		So(srv.WaitSucceeded(7101, 5*time.Second), ShouldBeNil)
+	})
+}
+
+func TestServer_Faults(t *testing.T) {
+	Convey("injected faults should surface through the real HTTP client", t, func() {
+		srv := powerjobtest.New(t, powerjobtest.WithApp("fault-app", 9))
+		api := client.NewHTTPServerAPI(client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}))
+		ctx := context.Background()
+
+		srv.Inject(powerjobtest.EndpointAssert, powerjobtest.Fault{Status: 503, Times: 1})
+		appID, err := api.AssertApp(ctx, srv.Addr(), "fault-app")
+		So(err, ShouldBeNil)
+		So(appID, ShouldEqual, 9)
+		So(srv.Requests(powerjobtest.EndpointAssert), ShouldEqual, 2)
+
+		_, err = api.AssertApp(ctx, srv.Addr(), "other-app")
+		var be *client.BusinessError
+		So(errors.As(err, &be), ShouldBeTrue)
+
+		srv.Inject(powerjobtest.EndpointReportLog, powerjobtest.Fault{Fail: "log storage full"})
+		err = api.ReportLog(ctx, srv.Addr(), client.WorkerLogReportReq{})
+		So(errors.As(err, &be), ShouldBeTrue)
+		So(be.Message, ShouldEqual, "log storage full")
+		srv.Clear(powerjobtest.EndpointReportLog)
+		So(api.ReportLog(ctx, srv.Addr(), client.WorkerLogReportReq{}), ShouldBeNil)
+
+		srv.Inject(powerjobtest.EndpointHeartbeat, powerjobtest.Fault{Latency: 200 * time.Millisecond})
+		slow := client.NewHTTPServerAPI(client.WithCallTimeout(client.CallHeartbeat, 20*time.Millisecond), client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}))
+		var ne *client.NetworkError
+		So(errors.As(slow.Heartbeat(ctx, srv.Addr(), client.WorkerHeartbeat{}), &ne), ShouldBeTrue)
+		So(srv.Heartbeats(), ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,25 @@
+package processor
+
+import "context"
+
+// TaskInfo 当前实例的派发信息，由 Worker 在调用 Run 前注入 ctx。
+type TaskInfo struct {
+	JobID          int64
+	InstanceID     int64
+	ProcessorKey   string // 控制台 processorInfo，即处理器 GetTaskKey
+	JobParams      string // 控制台配置的任务参数
+	InstanceParams string // 单次触发（OpenAPI/工作流）传入的实例参数，可能为空
+}
+
+type taskCtxKey struct{}
+
+// WithTask 将实例派发信息写入 Context（Worker 内部使用，测试中也可用于构造上下文）。
+func WithTask(ctx context.Context, t TaskInfo) context.Context {
+	return context.WithValue(ctx, taskCtxKey{}, t)
+}
+
+// TaskFromContext 读取实例派发信息；不在 Worker 派发链路中时返回 false。
+func TaskFromContext(ctx context.Context) (TaskInfo, bool) {
+	t, ok := ctx.Value(taskCtxKey{}).(TaskInfo)
+	return t, ok
+}
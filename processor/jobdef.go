@@ -0,0 +1,66 @@
+package processor
+
+import "time"
+
+// JobDefinition 处理器声明的任务定义（jobs as code），由 jobsync 在 Worker 启动时同步到 Server。
+// 说明：Cron 与 FixedRate 至多设置一个。零值字段表示不声明：新建任务时使用 Server 默认值（均为空时只能通过 API 触发），
+// 更新任务时保留 Server 现值（包括控制台修改过的值）；定义无法清空字段，需要时在控制台修改。
+type JobDefinition struct {
+	Name         string        // 任务名，默认使用处理器 key
+	Description  string        // 任务描述
+	Cron         string        // CRON 表达式（PowerJob 格式，含秒）
+	FixedRate    time.Duration // 固定频率
+	Params       string        // 任务参数（jobParams）
+	Timeout      time.Duration // 实例超时，新建任务为 0 时不限制
+	Retries      int           // 实例失败重试次数
+	MaxInstances int           // 同一任务同时运行的实例上限，新建任务为 0 时不限制
+	Concurrency  int           // 单机执行并发数，0 时新建任务使用 5（与控制台默认一致），更新时保留 Server 现值
+	Tags         []string      // 标签
+	Disabled     bool          // 同步后停用；为 false 时不改变 Server 上的启用状态（新建任务默认启用）
+}
+
+// JobDefiner 可选扩展：处理器实现该接口即可声明自己的任务定义。
+type JobDefiner interface {
+	JobDefinition() JobDefinition
+}
+
+// Define 为 key 声明任务定义，适用于 RegisterFunc 等无法实现 JobDefiner 的处理器；
+// 同一 key 同时存在两者时以 Define 为准。
+func (r *Registry) Define(key string, def JobDefinition) {
+	r.mu.Lock()
+	if r.defs == nil {
+		r.defs = map[string]JobDefinition{}
+	}
+	r.defs[key] = def
+	r.mu.Unlock()
+}
+
+// Definitions 返回已注册处理器声明的全部任务定义（按 key 索引），未注册处理器的 Define 会被忽略。
+func (r *Registry) Definitions() map[string]JobDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := map[string]JobDefinition{}
+	for key, p := range r.processors {
+		if d, ok := definerOf(p); ok {
+			out[key] = d.JobDefinition()
+		}
+		if def, ok := r.defs[key]; ok {
+			out[key] = def
+		}
+	}
+	return out
+}
+
+// Define 为默认注册表 Default 中的 key 声明任务定义。
+func Define(key string, def JobDefinition) { Default.Define(key, def) }
+
+// definerOf 识别处理器（含 NewTyped 适配的强类型处理器）声明的任务定义。
+func definerOf(p Processor) (JobDefiner, bool) {
+	if d, ok := p.(JobDefiner); ok {
+		return d, true
+	}
+	if a, ok := p.(interface{ definer() (JobDefiner, bool) }); ok {
+		return a.definer()
+	}
+	return nil, false
+}
@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type definedProc struct{ simpleTyped }
+
+func (definedProc) JobDefinition() JobDefinition { return JobDefinition{Cron: "0 0 2 * * ?"} }
+
+type simpleTyped struct{}
+
+func (simpleTyped) GetTaskKey() string                                 { return "def.typed" }
+func (simpleTyped) Run(ctx context.Context, in string) (string, error) { return in, nil }
+
+func TestRegistry_Definitions(t *testing.T) {
+	Convey("definitions come from JobDefiner processors and Define", t, func() {
+		r := NewRegistry()
+		r.Register(NewTyped[string, string](definedProc{}))
+		r.Register(NewFunc("def.func", func(ctx context.Context, in string) (string, error) { return in, nil }))
+		r.Register(NewFunc("def.none", func(ctx context.Context, in string) (string, error) { return in, nil }))
+		r.Define("def.func", JobDefinition{Params: "x"})
+		r.Define("def.unregistered", JobDefinition{})
+
+		defs := r.Definitions()
+		So(len(defs), ShouldEqual, 2)
+		So(defs["def.typed"].Cron, ShouldEqual, "0 0 2 * * ?")
+		So(defs["def.func"].Params, ShouldEqual, "x")
+
+		r.Define("def.typed", JobDefinition{Cron: "override"})
+		So(r.Definitions()["def.typed"].Cron, ShouldEqual, "override")
+	})
+}
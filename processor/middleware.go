@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/mengeric/powerjob-client-go/logging"
+)
+
+// Handler 处理器调用函数，签名与 Processor.Run 一致；当前实例信息可通过 TaskFromContext 获取。
+type Handler func(ctx context.Context, raw []byte) (Result, error)
+
+// Middleware 处理器中间件：包装 next 并返回新的 Handler，用于计时、指标、链路追踪、租户上下文注入、分布式锁等横切逻辑。
+type Middleware func(next Handler) Handler
+
+// Chain 组合中间件：Chain(h, a, b) 的调用顺序为 a -> b -> h，即第一个中间件位于最外层。
+func Chain(h Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			h = mws[i](h)
+		}
+	}
+	return h
+}
+
+// ErrPanic 处理器 panic 被捕获后返回的哨兵错误。
+var ErrPanic = errors.New("processor panic")
+
+// Recover 捕获 next 中的 panic 并转换为 ErrPanic 错误，避免单个实例拖垮整个进程。
+// Worker 默认在调用链最外层启用该中间件。
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, raw []byte) (res Result, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					// 堆栈只进日志（带实例上下文时会进入在线日志），结果消息保持简短
+					logging.L().Errorf(ctx, "processor panic: %v\n%s", r, debug.Stack())
+					err = fmt.Errorf("%w: %v", ErrPanic, r)
+				}
+			}()
+			return next(ctx, raw)
+		}
+	}
+}
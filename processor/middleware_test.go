@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMiddleware(t *testing.T) {
+	Convey("Chain should call middlewares outermost first", t, func() {
+		var trace []string
+		mark := func(name string) Middleware {
+			return func(next Handler) Handler {
+				return func(ctx context.Context, raw []byte) (Result, error) {
+					trace = append(trace, name+">")
+					res, err := next(ctx, raw)
+					trace = append(trace, "<"+name)
+					return res, err
+				}
+			}
+		}
+		h := Chain(func(ctx context.Context, raw []byte) (Result, error) {
+			trace = append(trace, "run")
+			return Result{Msg: string(raw)}, nil
+		}, mark("a"), nil, mark("b"))
+		res, err := h(context.Background(), []byte("x"))
+		So(err, ShouldBeNil)
+		So(res.Msg, ShouldEqual, "x")
+		So(trace, ShouldResemble, []string{"a>", "b>", "run", "<b", "<a"})
+	})
+
+	Convey("Recover should turn panics into ErrPanic", t, func() {
+		h := Chain(func(ctx context.Context, raw []byte) (Result, error) { panic("boom") }, Recover())
+		_, err := h(context.Background(), nil)
+		So(errors.Is(err, ErrPanic), ShouldBeTrue)
+		So(err.Error(), ShouldContainSubstring, "boom")
+	})
+}
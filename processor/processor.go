@@ -3,6 +3,7 @@ package processor
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 )
 
@@ -12,6 +13,15 @@ type Result struct {
 	Msg  string
 }
 
+// 组件内置结果码（业务自定义结果码请避开负数区间）。
+const (
+	CodeOK            = 0  // 成功
+	CodeNotFound      = -1 // 处理器不存在
+	CodeBadParams     = -2 // 参数解码失败
+	CodeInvalidParams = -3 // 参数校验失败
+	CodeInterrupted   = -4 // Worker 重启导致实例中断
+)
+
 // Processor 统一处理器接口。
 // 功能：执行业务逻辑；Stop 用于响应停止；GetTaskKey 返回控制台配置的 processorInfo。
 // 约定：GetTaskKey() 的返回值必须与控制台中的 processorInfo 完全一致（区分大小写）。
@@ -20,48 +30,67 @@ type Processor interface {
     GetTaskKey() string
     // Init 初始化钩子，可选。
     Init(ctx context.Context) error
-    // Run 执行业务，raw 为原始 JSON 字节，请自行绑定到强类型。
+    // Run 执行业务，raw 为原始 JSON 字节，请自行绑定到强类型（或使用 RegisterFunc/RegisterTyped 由组件绑定）。
     Run(ctx context.Context, raw []byte) (Result, error)
     // Stop 停止钩子，接收取消通知后进行清理。
     Stop(ctx context.Context) error
 }
 
-// TypedProcessor 是基于泛型的强类型处理器接口。
-// 功能：直接以强类型 S 接收参数，避免 map 解析与断言。
-// 使用：通过 RegisterTyped(name, impl) 注册，组件会负责把原始 JSON 绑定为 S。
-type TypedProcessor[S any] interface{}
+// Registry 处理器注册表。
+// 功能：按 GetTaskKey 维护一组处理器；不同 Worker 可持有各自的 Registry，实现同进程多应用隔离。
+// 说明：零值不可用，请通过 NewRegistry 创建；并发安全。
+type Registry struct {
+	mu         sync.RWMutex
+	processors map[string]Processor
+	defs       map[string]JobDefinition
+}
 
-var (
-    regMu      sync.RWMutex
-    processors = map[string]Processor{}
-)
+// NewRegistry 创建空的处理器注册表。
+func NewRegistry() *Registry { return &Registry{processors: map[string]Processor{}} }
 
-// Register 注册处理器。
-// 功能：使用 p.GetTaskKey() 作为键，不再需要额外传入名称，避免人为不一致。
+// Default 进程级默认注册表：包级 Register/Get 读写的即是它，未显式指定 Registry 的 Worker 也使用它。
+var Default = NewRegistry()
+
+// Register 向注册表注册处理器。
+// 功能：使用 p.GetTaskKey() 作为键。
 // 注意：若 key 为空将 panic；重复注册将覆盖旧值（请自行避免）。
-func Register(p Processor) {
-    key := p.GetTaskKey()
-    if key == "" {
-        panic("processor.GetTaskKey() must not be empty")
-    }
-    regMu.Lock()
-    processors[key] = p
-    regMu.Unlock()
+func (r *Registry) Register(p Processor) {
+	key := p.GetTaskKey()
+	if key == "" {
+		panic("processor.GetTaskKey() must not be empty")
+	}
+	r.mu.Lock()
+	r.processors[key] = p
+	r.mu.Unlock()
 }
 
-// Get 获取处理器。
-func Get(name string) (Processor, bool) {
-	regMu.RLock()
-	defer regMu.RUnlock()
-	p, ok := processors[name]
+// Get 按 key 获取处理器。
+func (r *Registry) Get(name string) (Processor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.processors[name]
 	return p, ok
 }
 
-// ErrNotFound 处理器不存在错误。
-var ErrNotFound = errors.New("processor not found")
+// Keys 返回已注册的全部 key（升序）。
+func (r *Registry) Keys() []string {
+	r.mu.RLock()
+	keys := make([]string, 0, len(r.processors))
+	for k := range r.processors {
+		keys = append(keys, k)
+	}
+	r.mu.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
 
-// RegisterTyped 已移除：请直接实现 Processor + GetTaskKey，在 Run 中自行解码 raw。
-func RegisterTyped[S any](name string, p TypedProcessor[S]) { panic("TypedProcessor removed; implement Processor + GetTaskKey and decode raw yourself") }
+// Register 注册处理器到默认注册表 Default。
+// 功能：使用 p.GetTaskKey() 作为键，不再需要额外传入名称，避免人为不一致。
+// 注意：若 key 为空将 panic；重复注册将覆盖旧值（请自行避免）。
+func Register(p Processor) { Default.Register(p) }
 
-// typedAdapter 将 TypedProcessor[S] 适配为旧 Processor 接口，便于 Worker 统一调用路径。
-type typedAdapter[S any] struct{}
+// Get 从默认注册表 Default 获取处理器。
+func Get(name string) (Processor, bool) { return Default.Get(name) }
+
+// ErrNotFound 处理器不存在错误。
+var ErrNotFound = errors.New("processor not found")
@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type keyProc struct{ key, tag string }
+
+func (p *keyProc) GetTaskKey() string             { return p.key }
+func (p *keyProc) Init(ctx context.Context) error { return nil }
+func (p *keyProc) Stop(ctx context.Context) error { return nil }
+func (p *keyProc) Run(ctx context.Context, raw []byte) (Result, error) {
+	return Result{Msg: p.tag}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	Convey("registries should be isolated from each other and from Default", t, func() {
+		a, b := NewRegistry(), NewRegistry()
+		a.Register(&keyProc{key: "same", tag: "a"})
+		b.Register(&keyProc{key: "same", tag: "b"})
+
+		pa, ok := a.Get("same")
+		So(ok, ShouldBeTrue)
+		ra, _ := pa.Run(context.Background(), nil)
+		So(ra.Msg, ShouldEqual, "a")
+
+		pb, ok := b.Get("same")
+		So(ok, ShouldBeTrue)
+		rb, _ := pb.Run(context.Background(), nil)
+		So(rb.Msg, ShouldEqual, "b")
+
+		_, ok = Get("same")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("package level Register should write Default and Keys should be sorted", t, func() {
+		Register(&keyProc{key: "zz.default"})
+		Register(&keyProc{key: "aa.default"})
+		_, ok := Default.Get("zz.default")
+		So(ok, ShouldBeTrue)
+		keys := Default.Keys()
+		So(keys, ShouldContain, "aa.default")
+		So(keys, ShouldContain, "zz.default")
+
+		So(func() { NewRegistry().Register(&keyProc{}) }, ShouldPanic)
+	})
+}
@@ -0,0 +1,30 @@
+package processor
+
+import "context"
+
+// ProgressFunc 进度回调，由 Worker 注入；percent 取值 0~100。
+type ProgressFunc func(percent int)
+
+type progressCtxKey struct{}
+
+// WithProgress 将进度回调写入 Context（Worker 内部使用，测试中也可用于捕获进度）。
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressCtxKey{}, fn)
+}
+
+// SetProgress 上报当前实例的执行进度，超出 0~100 的值会被截断。
+// 返回：不在 Worker 派发链路中（未注入回调）时返回 false。
+func SetProgress(ctx context.Context, percent int) bool {
+	fn, ok := ctx.Value(progressCtxKey{}).(ProgressFunc)
+	if !ok || fn == nil {
+		return false
+	}
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	fn(percent)
+	return true
+}
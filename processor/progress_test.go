@@ -0,0 +1,20 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetProgress(t *testing.T) {
+	Convey("SetProgress should clamp values and report whether a callback exists", t, func() {
+		So(SetProgress(context.Background(), 10), ShouldBeFalse)
+		var got []int
+		ctx := WithProgress(context.Background(), func(p int) { got = append(got, p) })
+		So(SetProgress(ctx, -5), ShouldBeTrue)
+		So(SetProgress(ctx, 40), ShouldBeTrue)
+		So(SetProgress(ctx, 120), ShouldBeTrue)
+		So(got, ShouldResemble, []int{0, 40, 100})
+	})
+}
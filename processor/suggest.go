@@ -0,0 +1,46 @@
+package processor
+
+import "strings"
+
+// Suggest 返回与 key 最相近的已注册 key，用于提示控制台 processorInfo 的拼写错误。
+// 规则：优先大小写不敏感的完全匹配；否则取编辑距离最小且不超过 max(2, len(key)/3) 的 key。
+// 返回：无合适候选时 ok=false。
+func (r *Registry) Suggest(key string) (string, bool) {
+	best, bestDist := "", -1
+	limit := len(key) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	for _, k := range r.Keys() {
+		if strings.EqualFold(k, key) {
+			return k, true
+		}
+		d := editDistance(strings.ToLower(k), strings.ToLower(key))
+		if d <= limit && (bestDist < 0 || d < bestDist) {
+			best, bestDist = k, d
+		}
+	}
+	return best, bestDist >= 0
+}
+
+// editDistance Levenshtein 编辑距离（按 rune 计算）。
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
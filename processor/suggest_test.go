@@ -0,0 +1,29 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegistry_Suggest(t *testing.T) {
+	Convey("Suggest should find case-insensitive and near matches only", t, func() {
+		r := NewRegistry()
+		for _, k := range []string{"order.settle", "order.refund", "report.daily"} {
+			r.Register(NewFunc(k, func(ctx context.Context, in string) (string, error) { return in, nil }))
+		}
+		got, ok := r.Suggest("Order.Settle")
+		So(ok, ShouldBeTrue)
+		So(got, ShouldEqual, "order.settle")
+		got, ok = r.Suggest("order.setle")
+		So(ok, ShouldBeTrue)
+		So(got, ShouldEqual, "order.settle")
+		got, ok = r.Suggest("report.dialy")
+		So(ok, ShouldBeTrue)
+		So(got, ShouldEqual, "report.daily")
+		_, ok = r.Suggest("invoice.send")
+		So(ok, ShouldBeFalse)
+		So(editDistance("kitten", "sitting"), ShouldEqual, 3)
+	})
+}
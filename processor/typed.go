@@ -0,0 +1,180 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrBadParams 参数解码失败的哨兵错误，可通过 errors.Is 判断。
+var ErrBadParams = errors.New("bad params")
+
+// ParamError 参数解码失败详情。
+type ParamError struct {
+	Key    string // 处理器 key
+	Source string // 参数来源：instanceParams 或 jobParams
+	Err    error  // 底层解码错误
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s: decode %s for %s: %v", ErrBadParams, e.Source, e.Key, e.Err)
+}
+
+// Unwrap 同时暴露哨兵错误与底层错误。
+func (e *ParamError) Unwrap() []error { return []error{ErrBadParams, e.Err} }
+
+// TypedProcessor 是基于泛型的强类型处理器接口。
+// 功能：直接以强类型 P 接收参数、返回强类型结果 R，避免在每个处理器中重复 json.Unmarshal。
+// 使用：通过 RegisterTyped(impl) 注册；如需 Init/Stop 钩子，实现同名方法即可被自动调用。
+type TypedProcessor[P, R any] interface {
+	// GetTaskKey 返回该处理器的唯一键（即控制台 processorInfo）。
+	GetTaskKey() string
+	// Run 执行业务，in 为组件解码后的参数。
+	Run(ctx context.Context, in P) (R, error)
+}
+
+// Func 强类型处理函数。
+type Func[P, R any] func(ctx context.Context, in P) (R, error)
+
+// NewTyped 将 TypedProcessor 适配为 Processor，便于注册到任意 Registry。
+// 注意：参数类型 P 的 `default:"..."` 标签格式错误（如 int 字段 default:"abc"）时 panic，在注册阶段而非每次派发时暴露。
+func NewTyped[P, R any](p TypedProcessor[P, R]) Processor {
+	mustValidDefaults[P](p.GetTaskKey())
+	return &typedAdapter[P, R]{impl: p}
+}
+
+// NewFunc 将强类型函数包装为 Processor；default 标签的检查同 NewTyped。
+func NewFunc[P, R any](key string, fn Func[P, R]) Processor {
+	return NewTyped[P, R](funcProcessor[P, R]{key: key, fn: fn})
+}
+
+// mustValidDefaults 以零值 P 试填 default 标签，解析失败时 panic。
+func mustValidDefaults[P any](key string) {
+	in := newParams[P]()
+	if err := ApplyDefaults(&in); err != nil {
+		panic(fmt.Sprintf("processor %s: bad default tag: %v", key, err))
+	}
+}
+
+// newParams 返回零值 P；P 为结构体指针时先分配，使默认值与校验作用于指向的结构体。
+func newParams[P any]() P {
+	var in P
+	if pv := reflect.ValueOf(&in).Elem(); pv.Kind() == reflect.Pointer && pv.Type().Elem().Kind() == reflect.Struct {
+		pv.Set(reflect.New(pv.Type().Elem()))
+	}
+	return in
+}
+
+// RegisterTyped 注册强类型处理器到默认注册表 Default。
+func RegisterTyped[P, R any](p TypedProcessor[P, R]) { Register(NewTyped(p)) }
+
+// RegisterFunc 以函数形式注册强类型处理器到默认注册表 Default。
+// 示例：processor.RegisterFunc("order.settle", func(ctx context.Context, in SettleParams) (SettleResult, error) {...})
+func RegisterFunc[P, R any](key string, fn Func[P, R]) { Register(NewFunc(key, fn)) }
+
+// funcProcessor 以函数实现 TypedProcessor。
+type funcProcessor[P, R any] struct {
+	key string
+	fn  Func[P, R]
+}
+
+func (f funcProcessor[P, R]) GetTaskKey() string { return f.key }
+func (f funcProcessor[P, R]) Run(ctx context.Context, in P) (R, error) {
+	return f.fn(ctx, in)
+}
+
+// typedAdapter 将 TypedProcessor[P, R] 适配为 Processor 接口，便于 Worker 统一调用路径。
+type typedAdapter[P, R any] struct{ impl TypedProcessor[P, R] }
+
+func (a *typedAdapter[P, R]) GetTaskKey() string { return a.impl.GetTaskKey() }
+
+// definer 暴露被适配处理器的 JobDefiner 实现，供 Registry.Definitions 识别。
+func (a *typedAdapter[P, R]) definer() (JobDefiner, bool) {
+	d, ok := any(a.impl).(JobDefiner)
+	return d, ok
+}
+
+// Init 若底层实现了 Init 则透传。
+func (a *typedAdapter[P, R]) Init(ctx context.Context) error {
+	if i, ok := a.impl.(interface{ Init(context.Context) error }); ok {
+		return i.Init(ctx)
+	}
+	return nil
+}
+
+// Stop 若底层实现了 Stop 则透传。
+func (a *typedAdapter[P, R]) Stop(ctx context.Context) error {
+	if s, ok := a.impl.(interface{ Stop(context.Context) error }); ok {
+		return s.Stop(ctx)
+	}
+	return nil
+}
+
+// Run 解码参数 -> 填充默认值并校验 -> 调用强类型 Run -> 序列化结果（R 为 Result 时原样返回）。
+// 参数来源：ctx 中实例参数（instanceParams）非空时优先，否则使用 raw（jobParams）。
+// 解码失败返回 CodeBadParams 与 *ParamError；校验失败返回 CodeInvalidParams 与 *ValidationError。
+func (a *typedAdapter[P, R]) Run(ctx context.Context, raw []byte) (Result, error) {
+	in, err := decodeParams[P](ctx, a.impl.GetTaskKey(), raw)
+	if err != nil {
+		return Result{Code: CodeBadParams, Msg: err.Error()}, err
+	}
+	if err := validateParams(a.impl.GetTaskKey(), &in); err != nil {
+		return Result{Code: CodeInvalidParams, Msg: err.Error()}, err
+	}
+	out, err := a.impl.Run(ctx, in)
+	if r, ok := any(out).(Result); ok {
+		return r, err
+	}
+	return Result{Code: CodeOK, Msg: encodeResult(out)}, err
+}
+
+// decodeParams 选择参数来源并解码为 P。
+// 说明：P 为 string/[]byte 时直接透传原文；参数为空时返回填充默认值后的 P。
+func decodeParams[P any](ctx context.Context, key string, raw []byte) (P, error) {
+	var in P
+	src, data := "jobParams", raw
+	if t, ok := TaskFromContext(ctx); ok && t.InstanceParams != "" {
+		src, data = "instanceParams", []byte(t.InstanceParams)
+	}
+	switch p := any(&in).(type) {
+	case *string:
+		*p = string(data)
+		return in, nil
+	case *[]byte:
+		*p = data
+		return in, nil
+	}
+	in = newParams[P]()
+	// 先填默认值再解码：JSON 中缺省的字段保留默认值，显式给出的值（含零值）以 JSON 为准；
+	// 标签格式已在 NewTyped 时检查过
+	if err := ApplyDefaults(&in); err != nil {
+		return in, &ParamError{Key: key, Source: "default tag", Err: err}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return in, nil
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return in, &ParamError{Key: key, Source: src, Err: err}
+	}
+	return in, nil
+}
+
+// encodeResult 将结果序列化为结果消息：string 原样返回，其余类型编码为 JSON。
+func encodeResult(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type settleIn struct {
+	OrderID string `json:"orderId"`
+	Force   bool   `json:"force"`
+}
+
+type settleOut struct {
+	Settled int `json:"settled"`
+}
+
+type typedSettle struct{ inited bool }
+
+func (p *typedSettle) GetTaskKey() string             { return "typed.settle" }
+func (p *typedSettle) Init(ctx context.Context) error { p.inited = true; return nil }
+func (p *typedSettle) Run(ctx context.Context, in settleIn) (string, error) {
+	return "settled " + in.OrderID, nil
+}
+
+func TestTypedProcessor(t *testing.T) {
+	Convey("NewFunc should decode jobParams and encode result as JSON", t, func() {
+		p := NewFunc("settle", func(ctx context.Context, in settleIn) (settleOut, error) {
+			So(in.OrderID, ShouldEqual, "A1")
+			So(in.Force, ShouldBeTrue)
+			return settleOut{Settled: 3}, nil
+		})
+		res, err := p.Run(context.Background(), []byte(`{"orderId":"A1","force":true}`))
+		So(err, ShouldBeNil)
+		So(res.Code, ShouldEqual, CodeOK)
+		So(res.Msg, ShouldEqual, `{"settled":3}`)
+	})
+
+	Convey("instanceParams should take precedence over jobParams", t, func() {
+		var got string
+		p := NewFunc("settle", func(ctx context.Context, in settleIn) (string, error) { got = in.OrderID; return "ok", nil })
+		ctx := WithTask(context.Background(), TaskInfo{JobParams: `{"orderId":"job"}`, InstanceParams: `{"orderId":"ins"}`})
+		res, err := p.Run(ctx, []byte(`{"orderId":"job"}`))
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "ins")
+		So(res.Msg, ShouldEqual, "ok")
+	})
+
+	Convey("decode failure should be reported as ErrBadParams", t, func() {
+		called := false
+		p := NewFunc("settle", func(ctx context.Context, in settleIn) (string, error) { called = true; return "", nil })
+		res, err := p.Run(context.Background(), []byte(`{"orderId":1}`))
+		So(called, ShouldBeFalse)
+		So(errors.Is(err, ErrBadParams), ShouldBeTrue)
+		var pe *ParamError
+		So(errors.As(err, &pe), ShouldBeTrue)
+		So(pe.Source, ShouldEqual, "jobParams")
+		So(res.Code, ShouldEqual, CodeBadParams)
+	})
+
+	Convey("string params, empty params and typed processors with hooks", t, func() {
+		p := NewFunc("raw", func(ctx context.Context, in string) (string, error) { return in, nil })
+		res, _ := p.Run(context.Background(), []byte("plain text"))
+		So(res.Msg, ShouldEqual, "plain text")
+
+		q := NewFunc("empty", func(ctx context.Context, in settleIn) (Result, error) { return Result{Code: 7, Msg: in.OrderID}, nil })
+		res, err := q.Run(context.Background(), nil)
+		So(err, ShouldBeNil)
+		So(res.Code, ShouldEqual, 7)
+
+		impl := &typedSettle{}
+		tp := NewTyped[settleIn, string](impl)
+		So(tp.GetTaskKey(), ShouldEqual, "typed.settle")
+		So(tp.Init(context.Background()), ShouldBeNil)
+		So(impl.inited, ShouldBeTrue)
+		So(tp.Stop(context.Background()), ShouldBeNil)
+		res, _ = tp.Run(context.Background(), []byte(`{"orderId":"B2"}`))
+		So(res.Msg, ShouldEqual, "settled B2")
+	})
+}
@@ -0,0 +1,290 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidParams 参数校验失败的哨兵错误，可通过 errors.Is 判断。
+var ErrInvalidParams = errors.New("invalid params")
+
+// Validator 参数自校验接口：参数类型（或其指针）实现后，组件会在结构体标签校验通过后调用。
+type Validator interface {
+	Validate() error
+}
+
+// FieldError 单个字段的校验失败。
+type FieldError struct {
+	Field string // 字段路径（优先使用 json 名），如 order.amount
+	Rule  string // 触发的规则，如 required、min
+	Msg   string // 可读描述
+}
+
+// ValidationError 参数校验失败详情，Error() 输出适合直接展示给运维人员的文本。
+type ValidationError struct {
+	Key    string       // 处理器 key
+	Fields []FieldError // 标签规则失败的字段
+	Err    error        // Validate() 返回的错误
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields)+1)
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Msg)
+	}
+	if e.Err != nil {
+		parts = append(parts, e.Err.Error())
+	}
+	return fmt.Sprintf("%s for %s: %s", ErrInvalidParams, e.Key, strings.Join(parts, "; "))
+}
+
+// Unwrap 同时暴露哨兵错误与 Validate() 返回的错误。
+func (e *ValidationError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrInvalidParams, e.Err}
+	}
+	return []error{ErrInvalidParams}
+}
+
+// ApplyDefaults 按 `default:"..."` 标签为零值字段填充默认值（递归处理嵌套结构体）。
+// 参数：v 必须为结构体指针（多级指针会逐级解引用），否则直接返回 nil。
+// 支持类型：string、bool、整数、浮点、time.Duration、[]string（逗号分隔）。
+func ApplyDefaults(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return applyDefaults(rv.Elem())
+}
+
+func applyDefaults(sv reflect.Value) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := sv.Field(i)
+		if fv.Kind() == reflect.Struct && sf.Type != durationType {
+			if err := applyDefaults(fv); err != nil {
+				return err
+			}
+			continue
+		}
+		def, ok := sf.Tag.Lookup("default")
+		if !ok || !fv.IsZero() {
+			continue
+		}
+		if err := setFromString(fv, def); err != nil {
+			return fmt.Errorf("default for %s: %w", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateStruct 按 `validate:"..."` 标签校验结构体，返回全部失败字段。
+// 规则（逗号分隔）：
+//   - required：不能为零值；
+//   - min=N / max=N：数值比较大小，字符串/切片/map 比较长度；
+//   - oneof=a b c：取值必须为空格分隔列表之一。
+func ValidateStruct(v any) []FieldError {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var out []FieldError
+	validateStruct(rv, "", &out)
+	return out
+}
+
+func validateStruct(sv reflect.Value, prefix string, out *[]FieldError) {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := sv.Field(i)
+		name := prefix + fieldName(sf)
+		if tag, ok := sf.Tag.Lookup("validate"); ok {
+			for _, rule := range strings.Split(tag, ",") {
+				if fe, bad := checkRule(fv, name, strings.TrimSpace(rule)); bad {
+					*out = append(*out, fe)
+					break
+				}
+			}
+		}
+		if fv.Kind() == reflect.Struct && sf.Type != durationType {
+			validateStruct(fv, name+".", out)
+		}
+	}
+}
+
+// checkRule 校验单条规则；返回 true 表示失败。
+func checkRule(fv reflect.Value, name, rule string) (FieldError, bool) {
+	op, arg, _ := strings.Cut(rule, "=")
+	switch op {
+	case "":
+		return FieldError{}, false
+	case "required":
+		if fv.IsZero() {
+			return FieldError{Field: name, Rule: op, Msg: "is required"}, true
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return FieldError{Field: name, Rule: op, Msg: "bad rule " + rule}, true
+		}
+		n, isLen, ok := measure(fv)
+		if !ok {
+			return FieldError{}, false
+		}
+		what := "value"
+		if isLen {
+			what = "length"
+		}
+		if op == "min" && n < limit {
+			return FieldError{Field: name, Rule: op, Msg: fmt.Sprintf("%s must be >= %s", what, arg)}, true
+		}
+		if op == "max" && n > limit {
+			return FieldError{Field: name, Rule: op, Msg: fmt.Sprintf("%s must be <= %s", what, arg)}, true
+		}
+	case "oneof":
+		if fv.IsZero() {
+			return FieldError{}, false
+		}
+		got := fmt.Sprint(fv.Interface())
+		for _, c := range strings.Fields(arg) {
+			if c == got {
+				return FieldError{}, false
+			}
+		}
+		return FieldError{Field: name, Rule: op, Msg: fmt.Sprintf("must be one of [%s], got %q", arg, got)}, true
+	default:
+		return FieldError{Field: name, Rule: op, Msg: "unknown rule " + op}, true
+	}
+	return FieldError{}, false
+}
+
+// measure 返回用于 min/max 比较的量：数值本身或长度。
+func measure(fv reflect.Value) (n float64, isLen bool, ok bool) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(fv.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(fv.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return fv.Float(), false, true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(fv.Len()), true, true
+	}
+	return 0, false, false
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setFromString 将默认值文本写入字段。
+func setFromString(fv reflect.Value, s string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", fv.Type())
+		}
+		parts := strings.Split(s, ",")
+		sl := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			sl.Index(i).SetString(strings.TrimSpace(p))
+		}
+		fv.Set(sl)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+// fieldName 优先返回 json 标签名，便于与控制台参数对照。
+func fieldName(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("json"); tag != "" {
+		if n, _, _ := strings.Cut(tag, ","); n != "" && n != "-" {
+			return n
+		}
+	}
+	return sf.Name
+}
+
+// validateParams 依次执行标签校验与 Validator 自校验。
+func validateParams(key string, in any) error {
+	fields := ValidateStruct(in)
+	var verr error
+	if len(fields) == 0 {
+		if v, ok := validatorOf(in); ok {
+			verr = v.Validate()
+		}
+	}
+	if len(fields) == 0 && verr == nil {
+		return nil
+	}
+	return &ValidationError{Key: key, Fields: fields, Err: verr}
+}
+
+// validatorOf 沿指针链查找 Validator 实现，兼容参数类型本身即为指针（如 P 为 *Cfg）的情况。
+func validatorOf(in any) (Validator, bool) {
+	rv := reflect.ValueOf(in)
+	for rv.IsValid() {
+		if v, ok := rv.Interface().(Validator); ok {
+			return v, true
+		}
+		if rv.Kind() != reflect.Pointer || rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+	return nil, false
+}
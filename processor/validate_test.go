@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type retryCfg struct {
+	Times   int           `json:"times" default:"3" validate:"min=1,max=10"`
+	Backoff time.Duration `json:"backoff" default:"2s"`
+}
+
+type transferIn struct {
+	Account string   `json:"account" validate:"required"`
+	Amount  int64    `json:"amount" validate:"min=1"`
+	Channel string   `json:"channel" default:"bank" validate:"oneof=bank wallet"`
+	Tags    []string `json:"tags" default:"a,b" validate:"max=3"`
+	Retry   retryCfg `json:"retry"`
+}
+
+// Validate 跨字段校验：钱包渠道单笔不超过 1000。
+func (t transferIn) Validate() error {
+	if t.Channel == "wallet" && t.Amount > 1000 {
+		return errors.New("wallet amount must be <= 1000")
+	}
+	return nil
+}
+
+type badDefaultIn struct {
+	Retry struct {
+		Times int `json:"times" default:"three"`
+	} `json:"retry"`
+}
+
+func TestValidation(t *testing.T) {
+	Convey("malformed default tags should fail at construction instead of per dispatch", t, func() {
+		fn := func(ctx context.Context, in badDefaultIn) (string, error) { return "", nil }
+		So(func() { NewFunc("bad.default", fn) }, ShouldPanicWith, `processor bad.default: bad default tag: default for Times: strconv.ParseInt: parsing "three": invalid syntax`)
+		ptrFn := func(ctx context.Context, in *badDefaultIn) (string, error) { return "", nil }
+		So(func() { NewFunc("bad.default.ptr", ptrFn) }, ShouldPanic)
+		okFn := func(ctx context.Context, in transferIn) (string, error) { return "", nil }
+		So(func() { NewFunc("transfer", okFn) }, ShouldNotPanic)
+	})
+
+	Convey("defaults should fill absent fields only", t, func() {
+		var got transferIn
+		p := NewFunc("transfer", func(ctx context.Context, in transferIn) (string, error) { got = in; return "ok", nil })
+		_, err := p.Run(context.Background(), []byte(`{"account":"x","amount":5,"retry":{"times":2}}`))
+		So(err, ShouldBeNil)
+		So(got.Channel, ShouldEqual, "bank")
+		So(got.Tags, ShouldResemble, []string{"a", "b"})
+		So(got.Retry.Times, ShouldEqual, 2)
+		So(got.Retry.Backoff, ShouldEqual, 2*time.Second)
+	})
+
+	Convey("tag rules should collect every failing field with readable messages", t, func() {
+		called := false
+		p := NewFunc("transfer", func(ctx context.Context, in transferIn) (string, error) { called = true; return "", nil })
+		res, err := p.Run(context.Background(), []byte(`{"amount":0,"channel":"cash","retry":{"times":20}}`))
+		So(called, ShouldBeFalse)
+		So(errors.Is(err, ErrInvalidParams), ShouldBeTrue)
+		So(res.Code, ShouldEqual, CodeInvalidParams)
+		var ve *ValidationError
+		So(errors.As(err, &ve), ShouldBeTrue)
+		So(len(ve.Fields), ShouldEqual, 4)
+		msg := err.Error()
+		So(msg, ShouldContainSubstring, "account: is required")
+		So(msg, ShouldContainSubstring, "amount: value must be >= 1")
+		So(msg, ShouldContainSubstring, `channel: must be one of [bank wallet], got "cash"`)
+		So(msg, ShouldContainSubstring, "retry.times: value must be <= 10")
+	})
+
+	Convey("Validate method should run after tag rules pass", t, func() {
+		p := NewFunc("transfer", func(ctx context.Context, in transferIn) (string, error) { return "", nil })
+		_, err := p.Run(context.Background(), []byte(`{"account":"x","amount":5000,"channel":"wallet"}`))
+		So(errors.Is(err, ErrInvalidParams), ShouldBeTrue)
+		So(strings.HasSuffix(err.Error(), "wallet amount must be <= 1000"), ShouldBeTrue)
+	})
+	Convey("pointer params should get defaults and run Validate too", t, func() {
+		var got *transferIn
+		p := NewFunc("transfer", func(ctx context.Context, in *transferIn) (string, error) { got = in; return "", nil })
+		_, err := p.Run(context.Background(), []byte(`{"account":"x","amount":5}`))
+		So(err, ShouldBeNil)
+		So(got.Channel, ShouldEqual, "bank")
+		So(got.Retry.Backoff, ShouldEqual, 2*time.Second)
+
+		_, err = p.Run(context.Background(), []byte(`{"account":"x","amount":5000,"channel":"wallet"}`))
+		So(errors.Is(err, ErrInvalidParams), ShouldBeTrue)
+		So(strings.HasSuffix(err.Error(), "wallet amount must be <= 1000"), ShouldBeTrue)
+
+		_, err = p.Run(context.Background(), nil)
+		So(errors.Is(err, ErrInvalidParams), ShouldBeTrue)
+	})
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"sync/atomic"
 	"time"
 
@@ -43,7 +44,7 @@ func (d *Discovery) Start(ctx context.Context) {
 				cur := d.Get()
 				addr, err := d.api.Acquire(ctx, d.bootstrap, d.appID, cur, d.version)
                 if err != nil {
-                    logging.L().Warnf(ctx, "acquire server failed: %v", err)
+                    logFailure(ctx, "acquire server", err)
                     continue
                 }
 				if addr != "" {
@@ -54,6 +55,20 @@ func (d *Discovery) Start(ctx context.Context) {
 	}()
 }
 
+// logFailure 记录调用失败：熔断打开时每个周期都会快速失败，降为 Debug 避免刷屏；
+// Server 以 success=false 拒绝时输出其消息，便于排查（如 Worker 未注册、日志被拒收）。
+func logFailure(ctx context.Context, what string, err error) {
+	var be *client.BusinessError
+	switch {
+	case errors.Is(err, client.ErrCircuitOpen):
+		logging.L().Debugf(ctx, "%s skipped: %v", what, err)
+	case errors.As(err, &be):
+		logging.L().Warnf(ctx, "%s rejected by server: %s", what, be.Message)
+	default:
+		logging.L().Warnf(ctx, "%s failed: %v", what, err)
+	}
+}
+
 // Get 返回当前 server 地址。
 func (d *Discovery) Get() string {
 	v := d.current.Load()
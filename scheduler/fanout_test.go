@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fanOutAPI 记录逐个上报的实例与最大并发。
+type fanOutAPI struct {
+	client.ServerAPI
+	mu       sync.Mutex
+	singles  []int64
+	inflight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (b *fanOutAPI) ReportInstanceStatus(ctx context.Context, addr string, req client.TaskTrackerReportInstanceStatusReq) error {
+	n := b.inflight.Add(1)
+	defer b.inflight.Add(-1)
+	for {
+		p := b.peak.Load()
+		if n <= p || b.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	b.mu.Lock()
+	b.singles = append(b.singles, req.InstanceID)
+	b.mu.Unlock()
+	return nil
+}
+
+func running(n int) []Running {
+	out := make([]Running, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, Running{JobID: 1, InstanceID: int64(i), Status: 3})
+	}
+	return out
+}
+
+func TestReporter_FanOut(t *testing.T) {
+	Convey("reporter should report instances one by one with bounded parallelism", t, func() {
+		api := &fanOutAPI{}
+		disc := NewDiscovery(api, 1, "127.0.0.1:10010", "0.1.0", 1)
+		rep := NewReporter(api, disc, fakeLister{items: running(20)}, "w", 1, WithReportParallelism(3))
+		rep.report(context.Background())
+		So(len(api.singles), ShouldEqual, 20)
+		So(api.peak.Load(), ShouldBeLessThanOrEqualTo, 3)
+		So(api.peak.Load(), ShouldBeGreaterThan, 1)
+	})
+}
+
+func TestReporter_SkipUnchanged(t *testing.T) {
+	Convey("unchanged running instances should only be re-reported after the keep-alive", t, func() {
+		api := &fanOutAPI{}
+		disc := NewDiscovery(api, 1, "127.0.0.1:10010", "0.1.0", 1)
+		rep := NewReporter(api, disc, fakeLister{}, "w", 1, WithReportKeepAlive(time.Minute))
+		list := []Running{{InstanceID: 1, Status: 3, Progress: 10}, {InstanceID: 2, Status: 3}}
+		now := time.Now()
+
+		So(len(rep.due(list, now)), ShouldEqual, 2)
+		for _, it := range list {
+			rep.done(context.Background(), it)
+		}
+		So(rep.due(list, now.Add(time.Second)), ShouldBeEmpty)
+
+		list[0].Progress = 50
+		list = append(list, Running{InstanceID: 3, Status: 5, Terminal: true})
+		due := rep.due(list, now.Add(time.Second))
+		So(len(due), ShouldEqual, 2)
+		So(due[0].InstanceID, ShouldEqual, 1)
+		So(due[1].InstanceID, ShouldEqual, 3)
+
+		So(len(rep.due(list, now.Add(2*time.Minute))), ShouldEqual, 3)
+
+		Convey("instances gone from the list are forgotten", func() {
+			rep.due(list[:1], now)
+			So(rep.sent, ShouldNotContainKey, int64(2))
+		})
+
+		Convey("a negative keep-alive reports every tick", func() {
+			r := NewReporter(api, disc, fakeLister{}, "w", 1, WithReportKeepAlive(-1))
+			r.done(context.Background(), list[1])
+			So(len(r.due(list[1:2], now)), ShouldEqual, 1)
+		})
+	})
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/mengeric/powerjob-client-go/client"
-	"github.com/mengeric/powerjob-client-go/logging"
 	"github.com/mengeric/powerjob-client-go/metrics"
 )
 
@@ -41,7 +40,7 @@ func (h *HeartbeatScheduler) Start(ctx context.Context) {
 					SystemMetrics: metrics.CollectSystemMetric(ctx),
 				}
                 if err := h.api.Heartbeat(ctx, h.disc.Get(), hb); err != nil {
-                    logging.L().Warnf(ctx, "heartbeat failed: %v", err)
+                    logFailure(ctx, "heartbeat", err)
                 }
 			}
 		}
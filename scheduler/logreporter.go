@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"github.com/mengeric/powerjob-client-go/client"
 	"github.com/mengeric/powerjob-client-go/logging"
 	"time"
@@ -46,7 +47,7 @@ func (l *LogReporter) Start(ctx context.Context) {
 			}
 			req := client.WorkerLogReportReq{InstanceLogContents: buf, WorkerAddress: l.worker}
             if err := l.api.ReportLog(ctx, l.disc.Get(), req); err != nil {
-                logging.L().Warnf(ctx, "report log failed: count=%d err=%v", len(buf), err)
+                logFailure(ctx, fmt.Sprintf("report log (count=%d)", len(buf)), err)
             }
 			buf = buf[:0]
 		}
@@ -2,6 +2,10 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mengeric/powerjob-client-go/client"
@@ -15,6 +19,9 @@ type Running struct {
 	JobID      int64
 	InstanceID int64
 	Status     int
+	Progress   int    // 执行进度 0~100，用于判断状态是否变化
+	Result     string // 终态结果消息，运行中为空
+	Terminal   bool   // 是否为待补报的终态实例
 }
 
 // runningLister 仅需要列出运行中实例的精简信息。
@@ -22,17 +29,69 @@ type runningLister interface {
 	ListRunning(ctx context.Context) ([]Running, error)
 }
 
+// reportAcker 可选：终态实例上报成功后回调，用于持久化"已上报"标记，避免重复上报。
+type reportAcker interface {
+	AckReported(ctx context.Context, instanceID int64) error
+}
+
+const (
+	DefaultReportParallelism = 8                // 逐个上报时的默认并发数
+	DefaultReportKeepAlive   = 30 * time.Second // 状态未变化的运行中实例的默认保活上报间隔
+)
+
 type InstanceReporter struct {
-	api      client.ServerAPI
-	disc     *Discovery
-	repo     runningLister
-	worker   string
-	interval time.Duration
+	api       client.ServerAPI
+	disc      *Discovery
+	repo      runningLister
+	worker    string
+	interval  time.Duration
+	parallel  int
+	keepAlive time.Duration
+
+	mu   sync.Mutex
+	sent map[int64]sentState // 最近一次上报成功的运行中实例状态
+}
+
+// sentState 上报成功时的状态快照。
+type sentState struct {
+	status   int
+	progress int
+	at       time.Time
+}
+
+// ReporterOption NewReporter 的可选项。
+type ReporterOption func(*InstanceReporter)
+
+// WithReportParallelism 逐个上报的最大并发数，默认 DefaultReportParallelism。
+func WithReportParallelism(n int) ReporterOption {
+	return func(r *InstanceReporter) {
+		if n > 0 {
+			r.parallel = n
+		}
+	}
+}
+
+// WithReportKeepAlive 运行中实例状态与进度未变化时，距上次上报成功超过 d 才再次上报；
+// d<0 表示每个周期都上报，默认 DefaultReportKeepAlive。终态实例在 ack 前每个周期都会上报。
+func WithReportKeepAlive(d time.Duration) ReporterOption {
+	return func(r *InstanceReporter) {
+		if d != 0 {
+			r.keepAlive = d
+		}
+	}
 }
 
 // NewReporter 构造。
-func NewReporter(api client.ServerAPI, disc *Discovery, repo runningLister, worker string, seconds int) *InstanceReporter {
-	return &InstanceReporter{api: api, disc: disc, repo: repo, worker: worker, interval: time.Duration(seconds) * time.Second}
+func NewReporter(api client.ServerAPI, disc *Discovery, repo runningLister, worker string, seconds int, opts ...ReporterOption) *InstanceReporter {
+	r := &InstanceReporter{
+		api: api, disc: disc, repo: repo, worker: worker, interval: time.Duration(seconds) * time.Second,
+		parallel: DefaultReportParallelism, keepAlive: DefaultReportKeepAlive,
+		sent: map[int64]sentState{},
+	}
+	for _, fn := range opts {
+		fn(r)
+	}
+	return r
 }
 
 // Start 启动上报任务。
@@ -45,24 +104,113 @@ func (r *InstanceReporter) Start(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				list, err := r.repo.ListRunning(ctx)
-                if err != nil {
-                    logging.L().Warnf(ctx, "list running failed: %v", err)
-                    continue
-                }
-				for _, it := range list {
-					req := client.TaskTrackerReportInstanceStatusReq{
-						JobID:          it.JobID,
-						InstanceID:     it.InstanceID,
-						ReportTime:     time.Now().UnixMilli(),
-						SourceAddress:  r.worker,
-						InstanceStatus: it.Status,
+				r.report(ctx)
+			}
+		}
+	}()
+}
+
+// report 执行一轮上报：跳过未变化的实例，其余以有限并发逐个上报（PowerJob-Server 没有批量状态上报端点）。
+// 失败的实例（含 Server 以 success=false 拒绝）不 ack，下个周期重新上报。
+func (r *InstanceReporter) report(ctx context.Context) {
+	list, err := r.repo.ListRunning(ctx)
+	if err != nil {
+		logging.L().Warnf(ctx, "list running failed: %v", err)
+		return
+	}
+	due := r.due(list, time.Now())
+	if len(due) == 0 {
+		return
+	}
+	r.fanOut(ctx, r.disc.Get(), due)
+}
+
+// fanOut 以最多 parallel 个并发逐个上报；熔断打开后不再发起新的上报，剩余实例留待下个周期。
+func (r *InstanceReporter) fanOut(ctx context.Context, addr string, due []Running) {
+	sem := make(chan struct{}, r.parallel)
+	var wg sync.WaitGroup
+	var open atomic.Bool
+	for _, it := range due {
+		if open.Load() {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(it Running) {
+			defer func() { <-sem; wg.Done() }()
+			if err := r.api.ReportInstanceStatus(ctx, addr, r.request(it)); err != nil {
+				if errors.Is(err, client.ErrCircuitOpen) {
+					if !open.Swap(true) {
+						logging.L().Debugf(ctx, "report instance skipped: %v", err)
 					}
-                    if err := r.api.ReportInstanceStatus(ctx, r.disc.Get(), req); err != nil {
-                        logging.L().Warnf(ctx, "report instance failed: iid=%d err=%v", it.InstanceID, err)
-                    }
+					return
 				}
+				logFailure(ctx, fmt.Sprintf("report instance iid=%d", it.InstanceID), err)
+				return
 			}
+			r.done(ctx, it)
+		}(it)
+	}
+	wg.Wait()
+}
+
+// request 构造单个实例的上报请求。
+func (r *InstanceReporter) request(it Running) client.TaskTrackerReportInstanceStatusReq {
+	return client.TaskTrackerReportInstanceStatusReq{
+		JobID:          it.JobID,
+		InstanceID:     it.InstanceID,
+		ReportTime:     time.Now().UnixMilli(),
+		SourceAddress:  r.worker,
+		InstanceStatus: it.Status,
+		Result:         it.Result,
+	}
+}
+
+// due 过滤出本轮需要上报的实例：终态实例总是上报；运行中实例在状态或进度变化、
+// 或距上次上报成功超过 keepAlive 时上报。同时清理已不在列表中的实例记录。
+func (r *InstanceReporter) due(list []Running, now time.Time) []Running {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := make(map[int64]struct{}, len(list))
+	out := make([]Running, 0, len(list))
+	for _, it := range list {
+		seen[it.InstanceID] = struct{}{}
+		last, ok := r.sent[it.InstanceID]
+		if !it.Terminal && ok && r.keepAlive > 0 && last.status == it.Status && last.progress == it.Progress && now.Sub(last.at) < r.keepAlive {
+			continue
 		}
-	}()
+		out = append(out, it)
+	}
+	for id := range r.sent {
+		if _, ok := seen[id]; !ok {
+			delete(r.sent, id)
+		}
+	}
+	return out
+}
+
+// done 记录上报成功：运行中实例保存快照用于去重，终态实例回调 ack。
+func (r *InstanceReporter) done(ctx context.Context, it Running) {
+	r.mu.Lock()
+	if it.Terminal {
+		delete(r.sent, it.InstanceID)
+	} else {
+		r.sent[it.InstanceID] = sentState{status: it.Status, progress: it.Progress, at: time.Now()}
+	}
+	r.mu.Unlock()
+	r.ack(ctx, it)
+}
+
+// ack 终态上报成功后回调 repo；失败仅记录日志，下个周期会重复上报。
+func (r *InstanceReporter) ack(ctx context.Context, it Running) {
+	if !it.Terminal {
+		return
+	}
+	a, ok := r.repo.(reportAcker)
+	if !ok {
+		return
+	}
+	if err := a.AckReported(ctx, it.InstanceID); err != nil {
+		logging.L().Warnf(ctx, "ack reported failed: iid=%d err=%v", it.InstanceID, err)
+	}
 }
@@ -34,3 +34,77 @@ func TestReporter(t *testing.T) {
 		So(true, ShouldBeTrue)
 	})
 }
+
+// ackLister 记录 AckReported 调用。
+type ackLister struct {
+	fakeLister
+	acked chan int64
+}
+
+func (a ackLister) AckReported(ctx context.Context, id int64) error { a.acked <- id; return nil }
+
+func TestReporter_AckTerminal(t *testing.T) {
+	Convey("reporter should ack terminal instances after a successful report only", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		api := mocks.NewMockServerAPI(ctrl)
+		results := make(chan string, 8)
+		api.EXPECT().ReportInstanceStatus(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, addr string, req client.TaskTrackerReportInstanceStatusReq) error {
+				if req.InstanceID == 2 {
+					results <- req.Result
+				}
+				return nil
+			}).MinTimes(2)
+
+		disc := NewDiscovery(api, 1, "127.0.0.1:10010", "0.1.0", 1)
+		l := ackLister{
+			fakeLister: fakeLister{items: []Running{{JobID: 7, InstanceID: 1, Status: 3}, {JobID: 7, InstanceID: 2, Status: 5, Result: "done", Terminal: true}}},
+			acked:      make(chan int64, 4),
+		}
+		// 串行上报，保证 ack 时两次上报均已发生
+		rep := NewReporter(api, disc, l, "127.0.0.1:27777", 1, WithReportParallelism(1))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		rep.Start(ctx)
+		var got int64
+		select {
+		case got = <-l.acked:
+		case <-time.After(2 * time.Second):
+		}
+		So(got, ShouldEqual, 2)
+		So(<-results, ShouldEqual, "done")
+	})
+}
+
+func TestReporter_Rejected(t *testing.T) {
+	Convey("reporter should not ack terminal instances the server rejected", t, func() {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		api := mocks.NewMockServerAPI(ctrl)
+		calls := make(chan int64, 8)
+		api.EXPECT().ReportInstanceStatus(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, addr string, req client.TaskTrackerReportInstanceStatusReq) error {
+				calls <- req.InstanceID
+				return &client.BusinessError{Call: client.CallReportStatus, Message: "unknown worker"}
+			}).MinTimes(1)
+
+		disc := NewDiscovery(api, 1, "127.0.0.1:10010", "0.1.0", 1)
+		l := ackLister{
+			fakeLister: fakeLister{items: []Running{{JobID: 7, InstanceID: 3, Status: 5, Terminal: true}}},
+			acked:      make(chan int64, 4),
+		}
+		rep := NewReporter(api, disc, l, "127.0.0.1:27777", 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		rep.Start(ctx)
+		var got int64
+		select {
+		case got = <-calls:
+		case <-time.After(2 * time.Second):
+		}
+		So(got, ShouldEqual, 3)
+		time.Sleep(50 * time.Millisecond)
+		So(len(l.acked), ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,436 @@
+// Package filestore 提供基于本地文件的持久化 Storage 实现（无第三方依赖）。
+//
+// 存储布局（位于 Open 传入的目录）：
+//   - snapshot.jsonl：全量快照，每行一条实例记录；
+//   - wal.jsonl：追加写日志，每行一次变更（put/del），重启时在快照之上重放。
+//
+// WAL 条目数达到阈值后自动压缩：写临时快照 -> fsync -> 原子 rename -> 截断 WAL。
+// 任一步骤中断都不会丢数据：WAL 中的 put 为整条记录覆盖，重复重放是幂等的。
+// 追加失败时 WAL 截断回上一条完整记录的末尾（截断也失败时存储进入故障状态，之后的写入均返回错误），
+// 因此损坏行只可能出现在 WAL 末尾（崩溃时写入中断）；重放时忽略末尾的残缺行，中间的损坏行使 Open 返回 ErrCorrupt。
+//
+// 崩溃恢复：Open 时仍处于 StateRunning 的记录会被判定为失败（"worker restarted"），
+// 并保持 Reported=false，由 Worker 的状态上报任务补报给 Server。
+package filestore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/logging"
+	"github.com/mengeric/powerjob-client-go/powerjob"
+	"github.com/mengeric/powerjob-client-go/processor"
+)
+
+const (
+	snapshotFile = "snapshot.jsonl"
+	walFile      = "wal.jsonl"
+
+	opPut = "put"
+	opDel = "del"
+
+	// RestartedMsg 重启恢复时写入失败记录的结果消息。
+	RestartedMsg = "worker restarted"
+)
+
+// ErrNotFound 记录不存在（即 powerjob.ErrNotFound）。
+var ErrNotFound = powerjob.ErrNotFound
+
+// ErrClosed 存储已关闭。
+var ErrClosed = errors.New("filestore: closed")
+
+// ErrCorrupt 快照或 WAL 中间存在损坏行，继续打开会丢失其后的数据，需人工处理。
+var ErrCorrupt = errors.New("filestore: corrupt log")
+
+// walEntry WAL 单行格式。
+type walEntry struct {
+	Op  string                   `json:"op"`
+	ID  int64                    `json:"id,omitempty"`
+	Rec *powerjob.InstanceRecord `json:"rec,omitempty"`
+}
+
+// Option 文件存储可选项。
+type Option func(*Store)
+
+// WithCompactEvery 设置 WAL 压缩阈值（条目数），默认 1000。
+func WithCompactEvery(n int) Option { return func(s *Store) { s.compactEvery = n } }
+
+// WithSyncWrites 设置每次写入后是否 fsync，默认 true；关闭可提升吞吐但断电时可能丢失最近写入。
+func WithSyncWrites(on bool) Option { return func(s *Store) { s.sync = on } }
+
+// Store 文件存储实现，满足 powerjob.Storage 及 NotFoundReporter、PendingReportLister、Pruner、InstanceQuerier 扩展接口。
+type Store struct {
+	mu           sync.RWMutex
+	dir          string
+	m            map[int64]*powerjob.InstanceRecord
+	wal          *os.File
+	walEntries   int
+	walSize      int64 // WAL 中完整记录的字节数，追加失败时截断到此处
+	failed       error // WAL 残缺且无法截断，之后的写入均返回该错误
+	compactEvery int
+	sync         bool
+	recovered    []powerjob.InstanceRecord
+	nextID       uint
+}
+
+// Open 打开（或创建）目录下的文件存储，加载快照并重放 WAL，随后执行崩溃恢复。
+// 参数：dir 数据目录，不存在时自动创建；opts 可选项。
+// 返回：Store 或打开/恢复过程中的 IO 错误。
+func Open(dir string, opts ...Option) (*Store, error) {
+	s := &Store{dir: dir, m: map[int64]*powerjob.InstanceRecord{}, compactEvery: 1000, sync: true}
+	for _, fn := range opts {
+		fn(s)
+	}
+	if s.compactEvery <= 0 {
+		s.compactEvery = 1000
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if _, err := s.replay(filepath.Join(dir, snapshotFile)); err != nil {
+		return nil, err
+	}
+	n, err := s.replay(filepath.Join(dir, walFile))
+	if err != nil {
+		return nil, err
+	}
+	s.walEntries = n
+	// WAL 以追加模式打开；若尾部存在半行（崩溃时写入中断），先压缩一次得到干净的 WAL
+	s.wal, err = os.OpenFile(filepath.Join(dir, walFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.compactLocked(); err != nil {
+		_ = s.wal.Close()
+		return nil, err
+	}
+	if err := s.recoverRunning(); err != nil {
+		_ = s.wal.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// replay 逐行读取文件并应用到内存；末尾的损坏行视为崩溃时的残缺写入并忽略。
+// 返回：成功应用的条目数；损坏行之后仍有数据时返回 ErrCorrupt。
+func (s *Store) replay(path string) (int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	n, line, bad := 0, 0, 0
+	for sc.Scan() {
+		line++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		if bad > 0 {
+			return n, fmt.Errorf("%w: %s line %d", ErrCorrupt, path, bad)
+		}
+		var e walEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			bad = line
+			continue
+		}
+		s.apply(e)
+		n++
+	}
+	return n, sc.Err()
+}
+
+// apply 将一条变更应用到内存索引。
+func (s *Store) apply(e walEntry) {
+	switch e.Op {
+	case opPut:
+		if e.Rec == nil {
+			return
+		}
+		cp := *e.Rec
+		s.m[cp.InstanceID] = &cp
+		if cp.ID >= s.nextID {
+			s.nextID = cp.ID + 1
+		}
+	case opDel:
+		delete(s.m, e.ID)
+	}
+}
+
+// recoverRunning 将重启前仍在运行的实例判定为失败，并记录到 Recovered 列表。
+func (s *Store) recoverRunning() error {
+	now := time.Now()
+	for _, r := range s.m {
+		if r.Status != powerjob.StateRunning {
+			continue
+		}
+		r.Status = powerjob.StateFailed
+		r.ResultCode = processor.CodeInterrupted
+		r.ResultMsg = RestartedMsg
+		r.Reported = false
+		r.UpdatedAt = now
+		if err := s.appendLocked(walEntry{Op: opPut, Rec: r}); err != nil {
+			return err
+		}
+		s.recovered = append(s.recovered, *r)
+	}
+	s.maybeCompactLocked()
+	return nil
+}
+
+// Recovered 返回本次 Open 时由运行中判定为失败的实例（只读副本）。
+func (s *Store) Recovered() []powerjob.InstanceRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]powerjob.InstanceRecord(nil), s.recovered...)
+}
+
+// appendLocked 追加一条 WAL；调用方需持有写锁。
+func (s *Store) appendLocked(e walEntry) error {
+	if s.wal == nil {
+		return ErrClosed
+	}
+	if s.failed != nil {
+		return s.failed
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line := append(b, '\n')
+	if _, err := s.wal.Write(line); err != nil {
+		return s.rollbackLocked(err)
+	}
+	if s.sync {
+		if err := s.wal.Sync(); err != nil {
+			return s.rollbackLocked(err)
+		}
+	}
+	s.walSize += int64(len(line))
+	s.walEntries++
+	return nil
+}
+
+// rollbackLocked 追加失败后把 WAL 截断回上一条完整记录，避免残缺行之后继续追加；返回原始错误。
+func (s *Store) rollbackLocked(err error) error {
+	if terr := s.wal.Truncate(s.walSize); terr != nil {
+		s.failed = fmt.Errorf("filestore: wal append failed (%v) and cannot be rolled back: %w", err, terr)
+		return s.failed
+	}
+	return err
+}
+
+// maybeCompactLocked WAL 达到阈值时压缩；须在内存索引更新之后调用，保证快照包含最新写入。
+// 说明：调用时写入已追加到 WAL 并生效，压缩失败只记录日志，不作为写入错误返回；
+// WAL 条目数仍超过阈值，下一次写入会再次尝试压缩。
+func (s *Store) maybeCompactLocked() {
+	if s.walEntries < s.compactEvery {
+		return
+	}
+	if err := s.compactLocked(); err != nil {
+		logging.L().Warnf(context.Background(), "filestore: compact %s failed, will retry on next write: %v", s.dir, err)
+	}
+}
+
+// Compact 立即将当前全量数据写为快照并清空 WAL。
+func (s *Store) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wal == nil {
+		return ErrClosed
+	}
+	return s.compactLocked()
+}
+
+// compactLocked 写临时快照 -> fsync -> rename -> 截断 WAL；调用方需持有写锁。
+func (s *Store) compactLocked() error {
+	tmp := filepath.Join(s.dir, snapshotFile+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	for _, r := range s.m {
+		if err := enc.Encode(walEntry{Op: opPut, Rec: r}); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, snapshotFile)); err != nil {
+		return err
+	}
+	if d, err := os.Open(s.dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	if err := s.wal.Truncate(0); err != nil {
+		return fmt.Errorf("truncate wal: %w", err)
+	}
+	s.walEntries, s.walSize = 0, 0
+	return nil
+}
+
+// Close 关闭 WAL 文件；关闭后写操作返回 ErrClosed。
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wal == nil {
+		return nil
+	}
+	err := s.wal.Close()
+	s.wal = nil
+	return err
+}
+
+// Upsert 插入或更新实例记录。
+func (s *Store) Upsert(ctx context.Context, rec *powerjob.InstanceRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *rec
+	if old, ok := s.m[rec.InstanceID]; ok {
+		cp.ID = old.ID
+	} else {
+		cp.ID = s.nextID
+		s.nextID++
+	}
+	if cp.UpdatedAt.IsZero() {
+		cp.UpdatedAt = time.Now()
+	}
+	if err := s.appendLocked(walEntry{Op: opPut, Rec: &cp}); err != nil {
+		return err
+	}
+	s.m[rec.InstanceID] = &cp
+	s.maybeCompactLocked()
+	return nil
+}
+
+// UpdateStatus 更新实例状态。
+func (s *Store) UpdateStatus(ctx context.Context, instanceID int64, status int, resultCode int, resultMsg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.m[instanceID]
+	if !ok {
+		return ErrNotFound
+	}
+	cp := *r
+	cp.Status = status
+	cp.ResultCode = resultCode
+	cp.ResultMsg = resultMsg
+	cp.UpdatedAt = time.Now()
+	if err := s.appendLocked(walEntry{Op: opPut, Rec: &cp}); err != nil {
+		return err
+	}
+	s.m[instanceID] = &cp
+	s.maybeCompactLocked()
+	return nil
+}
+
+// ReportsNotFound 实现 powerjob.NotFoundReporter：记录不存在时 Get 返回 ErrNotFound。
+func (s *Store) ReportsNotFound() bool { return true }
+
+// Get 按 instanceID 读取记录。
+func (s *Store) Get(ctx context.Context, instanceID int64) (*powerjob.InstanceRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if r, ok := s.m[instanceID]; ok {
+		cp := *r
+		return &cp, nil
+	}
+	return nil, ErrNotFound
+}
+
+// ListRunning 列出运行中实例。
+func (s *Store) ListRunning(ctx context.Context) ([]powerjob.InstanceRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]powerjob.InstanceRecord, 0)
+	for _, v := range s.m {
+		if v.Status == powerjob.StateRunning {
+			out = append(out, *v)
+		}
+	}
+	return out, nil
+}
+
+// ListPendingReport 列出已终态但尚未上报成功的实例（含重启恢复判定失败的实例）。
+func (s *Store) ListPendingReport(ctx context.Context) ([]powerjob.InstanceRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]powerjob.InstanceRecord, 0)
+	for _, v := range s.m {
+		if powerjob.IsTerminal(v.Status) && !v.Reported {
+			out = append(out, *v)
+		}
+	}
+	return out, nil
+}
+
+// Delete 删除实例记录。
+func (s *Store) Delete(ctx context.Context, instanceID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.m[instanceID]; !ok {
+		return nil
+	}
+	if err := s.appendLocked(walEntry{Op: opDel, ID: instanceID}); err != nil {
+		return err
+	}
+	delete(s.m, instanceID)
+	s.maybeCompactLocked()
+	return nil
+}
+
+// Prune 按保留策略淘汰已结束且已上报的记录。
+func (s *Store) Prune(ctx context.Context, p powerjob.RetentionPolicy) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all := make([]powerjob.InstanceRecord, 0, len(s.m))
+	for _, v := range s.m {
+		all = append(all, *v)
+	}
+	ids := powerjob.SelectPrunable(all, p, time.Now())
+	for i, id := range ids {
+		if err := s.appendLocked(walEntry{Op: opDel, ID: id}); err != nil {
+			return i, err
+		}
+		delete(s.m, id)
+	}
+	s.maybeCompactLocked()
+	return len(ids), nil
+}
+
+// ListInstances 按条件分页查询实例记录。
+func (s *Store) ListInstances(ctx context.Context, q powerjob.InstanceQuery) (powerjob.InstancePage, error) {
+	s.mu.RLock()
+	out := make([]powerjob.InstanceRecord, 0)
+	for _, v := range s.m {
+		if q.Match(v) {
+			out = append(out, *v)
+		}
+	}
+	s.mu.RUnlock()
+	return q.Paginate(out), nil
+}
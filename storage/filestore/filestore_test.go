@@ -0,0 +1,213 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/powerjob"
+	"github.com/mengeric/powerjob-client-go/processor"
+	"github.com/mengeric/powerjob-client-go/storage/storagetest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("records should survive reopen", t, func() {
+		dir := t.TempDir()
+		s, err := Open(dir)
+		So(err, ShouldBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 1, JobID: 7, Status: powerjob.StateRunning, StartedAt: time.Now()}), ShouldBeNil)
+		So(s.UpdateStatus(ctx, 1, powerjob.StateSucceed, 0, "ok"), ShouldBeNil)
+		So(s.UpdateStatus(ctx, 99, powerjob.StateSucceed, 0, "ok"), ShouldEqual, ErrNotFound)
+		So(s.Close(), ShouldBeNil)
+
+		s2, err := Open(dir)
+		So(err, ShouldBeNil)
+		defer s2.Close()
+		r, err := s2.Get(ctx, 1)
+		So(err, ShouldBeNil)
+		So(r.Status, ShouldEqual, powerjob.StateSucceed)
+		So(r.ResultMsg, ShouldEqual, "ok")
+		So(s2.Recovered(), ShouldBeEmpty)
+	})
+
+	Convey("running records should be resolved as failed after restart and pending report", t, func() {
+		dir := t.TempDir()
+		s, err := Open(dir)
+		So(err, ShouldBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 2, JobID: 7, Status: powerjob.StateRunning}), ShouldBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 3, JobID: 7, Status: powerjob.StateSucceed, Reported: true}), ShouldBeNil)
+		// 模拟崩溃：不调用 Close
+		s2, err := Open(dir)
+		So(err, ShouldBeNil)
+		defer s2.Close()
+
+		rec := s2.Recovered()
+		So(len(rec), ShouldEqual, 1)
+		So(rec[0].InstanceID, ShouldEqual, 2)
+		r, _ := s2.Get(ctx, 2)
+		So(r.Status, ShouldEqual, powerjob.StateFailed)
+		So(r.ResultCode, ShouldEqual, processor.CodeInterrupted)
+		So(r.ResultMsg, ShouldEqual, RestartedMsg)
+		running, _ := s2.ListRunning(ctx)
+		So(running, ShouldBeEmpty)
+		pending, _ := s2.ListPendingReport(ctx)
+		So(len(pending), ShouldEqual, 1)
+		So(pending[0].InstanceID, ShouldEqual, 2)
+	})
+
+	Convey("compaction should keep data and a torn WAL tail should be ignored", t, func() {
+		dir := t.TempDir()
+		s, err := Open(dir, WithCompactEvery(3), WithSyncWrites(false))
+		So(err, ShouldBeNil)
+		for i := int64(1); i <= 5; i++ {
+			So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: i, JobID: 1, Status: powerjob.StateSucceed}), ShouldBeNil)
+		}
+		So(s.Close(), ShouldBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 6}), ShouldEqual, ErrClosed)
+
+		f, err := os.OpenFile(filepath.Join(dir, walFile), os.O_WRONLY|os.O_APPEND, 0o644)
+		So(err, ShouldBeNil)
+		_, _ = f.WriteString(`{"op":"put","rec":{"InstanceID":`)
+		_ = f.Close()
+
+		s2, err := Open(dir)
+		So(err, ShouldBeNil)
+		defer s2.Close()
+		for i := int64(1); i <= 5; i++ {
+			r, err := s2.Get(ctx, i)
+			So(err, ShouldBeNil)
+			So(r.Status, ShouldEqual, powerjob.StateSucceed)
+		}
+		So(s2.Compact(), ShouldBeNil)
+		fi, err := os.Stat(filepath.Join(dir, walFile))
+		So(err, ShouldBeNil)
+		So(fi.Size(), ShouldEqual, 0)
+	})
+}
+
+func TestFileStore_CorruptWAL(t *testing.T) {
+	ctx := context.Background()
+	Convey("a corrupt line in the middle of the WAL should fail Open instead of dropping later entries", t, func() {
+		dir := t.TempDir()
+		s, err := Open(dir, WithCompactEvery(100))
+		So(err, ShouldBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 1, Status: powerjob.StateSucceed}), ShouldBeNil)
+		So(s.Close(), ShouldBeNil)
+
+		f, err := os.OpenFile(filepath.Join(dir, walFile), os.O_WRONLY|os.O_APPEND, 0o644)
+		So(err, ShouldBeNil)
+		_, _ = f.WriteString(`{"op":"put","rec":{"Inst` + "\n" + `{"op":"put","rec":{"InstanceID":2,"Status":5}}` + "\n")
+		_ = f.Close()
+
+		_, err = Open(dir)
+		So(errors.Is(err, ErrCorrupt), ShouldBeTrue)
+	})
+
+	Convey("a failed append that cannot be rolled back should fail later writes", t, func() {
+		dir := t.TempDir()
+		s, err := Open(dir)
+		So(err, ShouldBeNil)
+		defer s.Close()
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 1, Status: powerjob.StateRunning}), ShouldBeNil)
+
+		// 换成只读句柄：写入与截断都会失败
+		ro, err := os.Open(filepath.Join(dir, walFile))
+		So(err, ShouldBeNil)
+		_ = s.wal.Close()
+		s.wal = ro
+		So(s.UpdateStatus(ctx, 1, powerjob.StateSucceed, 0, "ok"), ShouldNotBeNil)
+		So(s.failed, ShouldNotBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 2}), ShouldEqual, s.failed)
+		r, err := s.Get(ctx, 1)
+		So(err, ShouldBeNil)
+		So(r.Status, ShouldEqual, powerjob.StateRunning) // 失败的写入不生效
+	})
+}
+
+func TestFileStore_CompactFailure(t *testing.T) {
+	ctx := context.Background()
+	Convey("a failed compaction should not fail a write that already reached the WAL", t, func() {
+		dir := t.TempDir()
+		s, err := Open(dir, WithCompactEvery(1))
+		So(err, ShouldBeNil)
+		defer s.Close()
+		// 临时快照路径被目录占用，compactLocked 无法创建文件
+		blocker := filepath.Join(dir, snapshotFile+".tmp")
+		So(os.Mkdir(blocker, 0o755), ShouldBeNil)
+
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 1, Status: powerjob.StateRunning}), ShouldBeNil)
+		So(s.UpdateStatus(ctx, 1, powerjob.StateSucceed, 0, "ok"), ShouldBeNil)
+		r, err := s.Get(ctx, 1)
+		So(err, ShouldBeNil)
+		So(r.Status, ShouldEqual, powerjob.StateSucceed)
+
+		// 障碍移除后，下一次写入重试压缩
+		So(os.Remove(blocker), ShouldBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 2, Status: powerjob.StateRunning}), ShouldBeNil)
+		fi, err := os.Stat(filepath.Join(dir, walFile))
+		So(err, ShouldBeNil)
+		So(fi.Size(), ShouldEqual, 0)
+	})
+}
+
+func TestFileStore_Prune(t *testing.T) {
+	ctx := context.Background()
+	Convey("prune and delete should persist across reopen", t, func() {
+		dir := t.TempDir()
+		s, err := Open(dir)
+		So(err, ShouldBeNil)
+		old := time.Now().Add(-48 * time.Hour)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 1, Status: powerjob.StateSucceed, Reported: true, UpdatedAt: old}), ShouldBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 2, Status: powerjob.StateFailed, Reported: false, UpdatedAt: old}), ShouldBeNil)
+		So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: 3, Status: powerjob.StateSucceed, Reported: true}), ShouldBeNil)
+		n, err := s.Prune(ctx, powerjob.RetentionPolicy{MaxAge: time.Hour})
+		So(err, ShouldBeNil)
+		So(n, ShouldEqual, 1)
+		So(s.Delete(ctx, 3), ShouldBeNil)
+		So(s.Delete(ctx, 404), ShouldBeNil)
+		So(s.Close(), ShouldBeNil)
+
+		s2, err := Open(dir)
+		So(err, ShouldBeNil)
+		defer s2.Close()
+		_, err = s2.Get(ctx, 1)
+		So(err, ShouldEqual, ErrNotFound)
+		_, err = s2.Get(ctx, 3)
+		So(err, ShouldEqual, ErrNotFound)
+		_, err = s2.Get(ctx, 2)
+		So(err, ShouldBeNil)
+	})
+}
+
+func TestFileStore_ListInstances(t *testing.T) {
+	ctx := context.Background()
+	Convey("file store should support filtered listing", t, func() {
+		s, err := Open(t.TempDir())
+		So(err, ShouldBeNil)
+		defer s.Close()
+		for i := int64(1); i <= 4; i++ {
+			So(s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: i, JobID: i % 2, Status: powerjob.StateSucceed, StartedAt: time.Now()}), ShouldBeNil)
+		}
+		page, err := s.ListInstances(ctx, powerjob.InstanceQuery{JobID: 1, Limit: 1})
+		So(err, ShouldBeNil)
+		So(page.Total, ShouldEqual, 2)
+		So(len(page.Items), ShouldEqual, 1)
+	})
+}
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) powerjob.Storage {
+		s, err := Open(t.TempDir(), WithSyncWrites(false), WithCompactEvery(16))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	})
+}
@@ -0,0 +1,12 @@
+package memstore
+
+import (
+	"testing"
+
+	"github.com/mengeric/powerjob-client-go/powerjob"
+	"github.com/mengeric/powerjob-client-go/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) powerjob.Storage { return New() })
+}
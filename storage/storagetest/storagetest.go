@@ -0,0 +1,246 @@
+// Package storagetest 提供 powerjob.Storage 的一致性测试套件。
+//
+// 任意 Storage 实现都可以在自己的测试中调用 Run 自检：
+//
+//	func TestMyStore(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) powerjob.Storage { return mystore.New() })
+//	}
+//
+// 基础用例覆盖 Storage 接口本身；实现了 PendingReportLister、Pruner、InstanceQuerier
+// 等可选扩展时，会自动追加对应用例，未实现的扩展跳过。
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mengeric/powerjob-client-go/powerjob"
+)
+
+// Factory 每次调用返回一个全新的空存储实例；需要清理资源时可使用 t.Cleanup。
+type Factory func(t *testing.T) powerjob.Storage
+
+// Run 对 newStore 创建的存储执行全部一致性用例。
+func Run(t *testing.T, newStore Factory) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("UpsertGet", func(t *testing.T) {
+		s := newStore(t)
+		started := time.Now().Truncate(time.Millisecond)
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 1, JobID: 7, Status: powerjob.StateRunning, StartedAt: started})
+		r, err := s.Get(ctx, 1)
+		if err != nil {
+			t.Fatalf("Get after Upsert: %v", err)
+		}
+		if r.JobID != 7 || r.Status != powerjob.StateRunning || !r.StartedAt.Equal(started) {
+			t.Fatalf("Get returned %+v", r)
+		}
+		if r.UpdatedAt.IsZero() {
+			t.Errorf("Upsert should fill UpdatedAt when zero")
+		}
+		// 返回值应为副本，修改不影响存储
+		r.Status = powerjob.StateFailed
+		again, _ := s.Get(ctx, 1)
+		if again.Status != powerjob.StateRunning {
+			t.Errorf("Get should return a copy, store was mutated to %d", again.Status)
+		}
+		// Upsert 覆盖
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 1, JobID: 7, Status: powerjob.StateSucceed, ResultMsg: "ok"})
+		again, _ = s.Get(ctx, 1)
+		if again.Status != powerjob.StateSucceed || again.ResultMsg != "ok" {
+			t.Errorf("Upsert should overwrite, got %+v", again)
+		}
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		s := newStore(t)
+		_, err := s.Get(ctx, 404)
+		if !errors.Is(err, powerjob.ErrNotFound) {
+			t.Fatalf("Get of a missing record should return powerjob.ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		s := newStore(t)
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 2, JobID: 7, Status: powerjob.StateRunning, UpdatedAt: time.Now().Add(-time.Hour)})
+		before, _ := s.Get(ctx, 2)
+		if err := s.UpdateStatus(ctx, 2, powerjob.StateFailed, -1, "boom"); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		r, _ := s.Get(ctx, 2)
+		if r.Status != powerjob.StateFailed || r.ResultCode != -1 || r.ResultMsg != "boom" || r.JobID != 7 {
+			t.Errorf("UpdateStatus result %+v", r)
+		}
+		if !r.UpdatedAt.After(before.UpdatedAt) {
+			t.Errorf("UpdateStatus should advance UpdatedAt")
+		}
+		if err := s.UpdateStatus(ctx, 404, powerjob.StateFailed, 0, ""); !errors.Is(err, powerjob.ErrNotFound) {
+			t.Errorf("UpdateStatus of a missing record should return powerjob.ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ListRunning", func(t *testing.T) {
+		s := newStore(t)
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 1, Status: powerjob.StateRunning})
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 2, Status: powerjob.StateSucceed})
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 3, Status: powerjob.StateRunning})
+		got, err := s.ListRunning(ctx)
+		if err != nil {
+			t.Fatalf("ListRunning: %v", err)
+		}
+		if ids := idsOf(got); !equalIDs(ids, []int64{1, 3}) {
+			t.Errorf("ListRunning = %v, want [1 3]", ids)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		s := newStore(t)
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 25; i++ {
+					id := int64(g*100 + i)
+					_ = s.Upsert(ctx, &powerjob.InstanceRecord{InstanceID: id, Status: powerjob.StateRunning})
+					_ = s.UpdateStatus(ctx, id, powerjob.StateSucceed, 0, "ok")
+					_, _ = s.Get(ctx, id)
+					_, _ = s.ListRunning(ctx)
+				}
+			}(g)
+		}
+		wg.Wait()
+		running, _ := s.ListRunning(ctx)
+		if len(running) != 0 {
+			t.Errorf("all records should be finished, %d still running", len(running))
+		}
+	})
+
+	t.Run("PendingReportLister", func(t *testing.T) {
+		s := newStore(t)
+		pl, ok := s.(powerjob.PendingReportLister)
+		if !ok {
+			t.Skip("storage does not implement PendingReportLister")
+		}
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 1, Status: powerjob.StateRunning})
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 2, Status: powerjob.StateSucceed})
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 3, Status: powerjob.StateFailed, Reported: true})
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 4, Status: powerjob.StateStopped})
+		got, err := pl.ListPendingReport(ctx)
+		if err != nil {
+			t.Fatalf("ListPendingReport: %v", err)
+		}
+		if ids := idsOf(got); !equalIDs(ids, []int64{2, 4}) {
+			t.Errorf("ListPendingReport = %v, want [2 4]", ids)
+		}
+	})
+
+	t.Run("Pruner", func(t *testing.T) {
+		s := newStore(t)
+		pr, ok := s.(powerjob.Pruner)
+		if !ok {
+			t.Skip("storage does not implement Pruner")
+		}
+		old := time.Now().Add(-48 * time.Hour)
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 1, Status: powerjob.StateSucceed, Reported: true, UpdatedAt: old})
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 2, Status: powerjob.StateSucceed, Reported: false, UpdatedAt: old})
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 3, Status: powerjob.StateRunning, UpdatedAt: old})
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 4, Status: powerjob.StateSucceed, Reported: true})
+		n, err := pr.Prune(ctx, powerjob.RetentionPolicy{MaxAge: time.Hour})
+		if err != nil || n != 1 {
+			t.Fatalf("Prune = %d, %v; want 1, nil", n, err)
+		}
+		if _, err := s.Get(ctx, 1); err == nil {
+			t.Errorf("expired reported record should be pruned")
+		}
+		for _, id := range []int64{2, 3, 4} {
+			if _, err := s.Get(ctx, id); err != nil {
+				t.Errorf("record %d should be kept: %v", id, err)
+			}
+		}
+		if err := pr.Delete(ctx, 4); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := s.Get(ctx, 4); err == nil {
+			t.Errorf("deleted record should be gone")
+		}
+		if err := pr.Delete(ctx, 404); err != nil {
+			t.Errorf("Delete of a missing record should return nil, got %v", err)
+		}
+	})
+
+	t.Run("InstanceQuerier", func(t *testing.T) {
+		s := newStore(t)
+		q, ok := s.(powerjob.InstanceQuerier)
+		if !ok {
+			t.Skip("storage does not implement InstanceQuerier")
+		}
+		base := time.Now().Add(-10 * time.Hour)
+		for i := int64(1); i <= 5; i++ {
+			st := powerjob.StateSucceed
+			if i == 5 {
+				st = powerjob.StateFailed
+			}
+			at := base.Add(time.Duration(i) * time.Hour)
+			mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: i, JobID: 42, Status: st, StartedAt: at, UpdatedAt: at})
+		}
+		mustUpsert(t, s, &powerjob.InstanceRecord{InstanceID: 6, JobID: 7, Status: powerjob.StateSucceed, StartedAt: base, UpdatedAt: base})
+
+		page, err := q.ListInstances(ctx, powerjob.InstanceQuery{JobID: 42, Limit: 2, Offset: 1})
+		if err != nil {
+			t.Fatalf("ListInstances: %v", err)
+		}
+		if page.Total != 5 || !equalOrdered(idsOf(page.Items), []int64{4, 3}) {
+			t.Errorf("paged query = total %d items %v, want total 5 items [4 3]", page.Total, idsOf(page.Items))
+		}
+		page, _ = q.ListInstances(ctx, powerjob.InstanceQuery{Statuses: []int{powerjob.StateFailed}})
+		if page.Total != 1 || page.Items[0].InstanceID != 5 {
+			t.Errorf("status query = %v", idsOf(page.Items))
+		}
+		page, _ = q.ListInstances(ctx, powerjob.InstanceQuery{StartedFrom: base.Add(2 * time.Hour), StartedTo: base.Add(4 * time.Hour)})
+		if !equalIDs(idsOf(page.Items), []int64{2, 3}) {
+			t.Errorf("time window query = %v, want [2 3]", idsOf(page.Items))
+		}
+	})
+}
+
+func mustUpsert(t *testing.T, s powerjob.Storage, r *powerjob.InstanceRecord) {
+	t.Helper()
+	if err := s.Upsert(context.Background(), r); err != nil {
+		t.Fatalf("Upsert(%d): %v", r.InstanceID, err)
+	}
+}
+
+func idsOf(recs []powerjob.InstanceRecord) []int64 {
+	out := make([]int64, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, r.InstanceID)
+	}
+	return out
+}
+
+// equalIDs 忽略顺序比较。
+func equalIDs(got, want []int64) bool {
+	g := append([]int64(nil), got...)
+	w := append([]int64(nil), want...)
+	sort.Slice(g, func(i, j int) bool { return g[i] < g[j] })
+	sort.Slice(w, func(i, j int) bool { return w[i] < w[j] })
+	return equalOrdered(g, w)
+}
+
+func equalOrdered(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+// Package tlsutil 提供 Worker 服务端与 Server 客户端共用的 TLS 配置工具，支持证书热更新。
+//
+// Reloader 从磁盘加载 PEM 格式的证书、私钥与 CA，并在握手时按检查周期比对文件修改时间，
+// 文件变化后自动重新加载，无需重启 Worker。加载失败时继续使用上一份有效材料。
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCheckEvery 默认的文件变更检查周期。
+const DefaultCheckEvery = 10 * time.Second
+
+// Files TLS 材料文件路径（PEM）。
+type Files struct {
+	CertFile string // 本端证书（可含中间证书链）
+	KeyFile  string // 本端私钥
+	CAFile   string // 信任的 CA：服务端用于校验客户端证书，客户端用于校验服务端证书；留空时客户端使用系统根证书
+}
+
+// Reloader 持有当前生效的证书与 CA，并在文件变化后热更新。
+type Reloader struct {
+	files Files
+	every time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	stamps    [3]time.Time
+	lastCheck time.Time
+	lastErr   error
+}
+
+// NewReloader 加载 TLS 材料并返回 Reloader。
+// 参数：f 文件路径，CertFile/KeyFile 需同时提供或同时为空；every 检查周期（<=0 时取 DefaultCheckEvery）。
+// 异常：首次加载失败时返回错误。
+func NewReloader(f Files, every time.Duration) (*Reloader, error) {
+	if (f.CertFile == "") != (f.KeyFile == "") {
+		return nil, errors.New("tlsutil: CertFile and KeyFile must be set together")
+	}
+	if every <= 0 {
+		every = DefaultCheckEvery
+	}
+	r := &Reloader{files: f, every: every}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LastError 返回最近一次热更新失败的错误；成功加载后清空。
+func (r *Reloader) LastError() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lastErr
+}
+
+// ErrNoClientCA 要求客户端证书但未配置 CAFile：此时客户端证书会按系统根证书校验，任何公网可信证书都能通过。
+var ErrNoClientCA = errors.New("tlsutil: requireClientCert needs CAFile to verify client certificates")
+
+// ServerConfig 返回 Worker 服务端使用的 tls.Config。
+// 参数：requireClientCert 为 true 时启用双向 TLS，要求并校验由 CAFile 签发的客户端证书。
+// 异常：requireClientCert 为 true 但未配置 CAFile 时返回 ErrNoClientCA。
+func (r *Reloader) ServerConfig(requireClientCert bool) (*tls.Config, error) {
+	if requireClientCert && r.files.CAFile == "" {
+		return nil, ErrNoClientCA
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// 每次握手生成配置，使证书与客户端 CA 的更新立即生效
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.maybeReload()
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+			if r.cert != nil {
+				cfg.Certificates = []tls.Certificate{*r.cert}
+			}
+			if requireClientCert {
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+				cfg.ClientCAs = r.pool
+			}
+			return cfg, nil
+		},
+	}, nil
+}
+
+// ClientConfig 返回访问 PowerJob Server 使用的 tls.Config。
+// 说明：客户端证书（双向 TLS）按需热更新；RootCAs 取创建时加载的 CAFile，CA 变更需重新创建客户端。
+func (r *Reloader) ClientConfig() *tls.Config {
+	r.mu.RLock()
+	pool := r.pool
+	r.mu.RUnlock()
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: pool}
+	if r.files.CertFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			r.maybeReload()
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		}
+	}
+	return cfg
+}
+
+// maybeReload 距上次检查超过周期且文件有变化时重新加载。
+func (r *Reloader) maybeReload() {
+	r.mu.RLock()
+	due := time.Since(r.lastCheck) >= r.every
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+	if stamps := r.stat(); stamps != r.currentStamps() {
+		if err := r.reload(); err != nil {
+			r.mu.Lock()
+			r.lastErr = err
+			r.mu.Unlock()
+		}
+	}
+	r.mu.Lock()
+	r.lastCheck = time.Now()
+	r.mu.Unlock()
+}
+
+func (r *Reloader) currentStamps() [3]time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.stamps
+}
+
+// stat 读取三个文件的修改时间，未配置或读取失败的文件记为零值。
+func (r *Reloader) stat() [3]time.Time {
+	var out [3]time.Time
+	for i, p := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		if p == "" {
+			continue
+		}
+		if fi, err := os.Stat(p); err == nil {
+			out[i] = fi.ModTime()
+		}
+	}
+	return out
+}
+
+// reload 重新加载全部材料；任一失败则保留旧材料并返回错误。
+func (r *Reloader) reload() error {
+	stamps := r.stat()
+	var cert *tls.Certificate
+	if r.files.CertFile != "" {
+		c, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("tlsutil: load key pair: %w", err)
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		p, err := LoadCertPool(r.files.CAFile)
+		if err != nil {
+			return err
+		}
+		pool = p
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.pool, r.stamps, r.lastErr = cert, pool, stamps, nil
+	return nil
+}
+
+// LoadCertPool 从一个或多个 PEM 文件构造证书池。
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("tlsutil: read CA: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("tlsutil: no certificates found in %s", f)
+		}
+	}
+	return pool, nil
+}
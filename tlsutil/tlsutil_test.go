@@ -0,0 +1,162 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testCA 测试用自签 CA。
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newCA() *testCA {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue 签发叶子证书，返回证书与私钥 PEM。
+func (ca *testCA) issue(serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, tpl, ca.cert, &key.PublicKey, ca.key)
+	kb, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb})
+}
+
+func writeFiles(dir, prefix string, cert, key []byte) Files {
+	f := Files{CertFile: filepath.Join(dir, prefix+".crt"), KeyFile: filepath.Join(dir, prefix+".key")}
+	_ = os.WriteFile(f.CertFile, cert, 0o600)
+	_ = os.WriteFile(f.KeyFile, key, 0o600)
+	return f
+}
+
+// touchLater 将文件修改时间推后，保证 mtime 变化可被检测到。
+func touchLater(paths ...string) {
+	t := time.Now().Add(time.Minute)
+	for _, p := range paths {
+		_ = os.Chtimes(p, t, t)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	Convey("Reloader should serve mutual TLS and pick up rotated certificates", t, func() {
+		dir := t.TempDir()
+		ca := newCA()
+		caFile := filepath.Join(dir, "ca.pem")
+		So(os.WriteFile(caFile, ca.pem, 0o600), ShouldBeNil)
+
+		sc, sk := ca.issue(100, x509.ExtKeyUsageServerAuth)
+		sf := writeFiles(dir, "server", sc, sk)
+		sf.CAFile = caFile
+		srv, err := NewReloader(sf, 10*time.Millisecond)
+		So(err, ShouldBeNil)
+
+		cc, ck := ca.issue(200, x509.ExtKeyUsageClientAuth)
+		cf := writeFiles(dir, "client", cc, ck)
+		cf.CAFile = caFile
+		cli, err := NewReloader(cf, 10*time.Millisecond)
+		So(err, ShouldBeNil)
+
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			_, _ = rw.Write([]byte(r.TLS.PeerCertificates[0].SerialNumber.String()))
+		}))
+		ts.TLS, err = srv.ServerConfig(true)
+		So(err, ShouldBeNil)
+		ts.StartTLS()
+		defer ts.Close()
+
+		serverSerial := func(cfg *tls.Config) (int64, error) {
+			hc := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg, DisableKeepAlives: true}}
+			resp, err := hc.Get(ts.URL)
+			if err != nil {
+				return 0, err
+			}
+			_ = resp.Body.Close()
+			return resp.TLS.PeerCertificates[0].SerialNumber.Int64(), nil
+		}
+
+		n, err := serverSerial(cli.ClientConfig())
+		So(err, ShouldBeNil)
+		So(n, ShouldEqual, 100)
+
+		Convey("mutual TLS without a client CA is rejected", func() {
+			noCA, err := NewReloader(Files{CertFile: sf.CertFile, KeyFile: sf.KeyFile}, 0)
+			So(err, ShouldBeNil)
+			_, err = noCA.ServerConfig(true)
+			So(errors.Is(err, ErrNoClientCA), ShouldBeTrue)
+			_, err = noCA.ServerConfig(false)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("clients without a certificate are refused", func() {
+			anon, _ := NewReloader(Files{CAFile: caFile}, 0)
+			_, err := serverSerial(anon.ClientConfig())
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("rotated server certificates are served without restart", func() {
+			nc, nk := ca.issue(101, x509.ExtKeyUsageServerAuth)
+			writeFiles(dir, "server", nc, nk)
+			touchLater(sf.CertFile, sf.KeyFile)
+			time.Sleep(20 * time.Millisecond)
+			n, err := serverSerial(cli.ClientConfig())
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 101)
+		})
+
+		Convey("broken files keep the previous material", func() {
+			_ = os.WriteFile(sf.CertFile, []byte("garbage"), 0o600)
+			touchLater(sf.CertFile)
+			time.Sleep(20 * time.Millisecond)
+			n, err := serverSerial(cli.ClientConfig())
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 100)
+			So(srv.LastError(), ShouldNotBeNil)
+		})
+	})
+
+	Convey("NewReloader should validate inputs", t, func() {
+		_, err := NewReloader(Files{CertFile: "a.crt"}, 0)
+		So(err, ShouldNotBeNil)
+		_, err = NewReloader(Files{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, 0)
+		So(err, ShouldNotBeNil)
+	})
+}